@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// crawl hunts the dead links of the seeds, or of the Markdown files
+func crawl(cfg *config.Config, args []string) {
+	if len(args) > 0 {
+		usageError("crawl", "Unexpected arguments %v", args)
+	}
+	if err := cfg.RequireSeeds(); err != nil {
+		usageError("crawl", "Invalid configuration: %v", err)
+	}
+
+	if cfg.Login != nil && (cfg.Static || cfg.Markdown != "") {
+		log.Printf("The login only runs in dynamic mode, use cookies or auth to authenticate other modes")
+	}
+	if cfg.Screenshots != "" && (cfg.Static || cfg.Hybrid || cfg.Markdown != "") {
+		log.Printf("Screenshots are only taken in dynamic mode")
+	}
+
+	var hunters []webscraper.WebScraper
+	if cfg.Markdown != "" {
+		hunters = append(hunters, webscraper.NewMarkdownHunter(cfg.Markdown))
+	} else {
+		for _, seed := range cfg.Seeds {
+			if cfg.Static {
+				hunters = append(hunters, webscraper.NewStaticHunter(seed))
+			} else if cfg.Hybrid {
+				hunters = append(hunters, webscraper.NewHybridHunter(seed))
+			} else {
+				hunters = append(hunters, webscraper.NewDynamicHunter(seed))
+			}
+		}
+	}
+	hunt(cfg, hunters)
+}
+
+// check checks the URLs of the input file and the arguments without crawling them
+func check(cfg *config.Config, args []string) {
+	if cfg.Input == "" && len(args) == 0 {
+		usageError("check", "No URLs to check, pass them as arguments or with -input")
+	}
+
+	var hunters []webscraper.WebScraper
+	if cfg.Input != "" {
+		source, r := cfg.Input, io.Reader(os.Stdin)
+		if cfg.Input == "-" {
+			source = "stdin"
+		} else {
+			file, err := os.Open(cfg.Input)
+			if err != nil {
+				log.Fatalf("Error opening %s: %v", cfg.Input, err)
+			}
+			defer file.Close()
+			r = file
+		}
+		dlh, err := webscraper.NewListHunterFromReader(source, r)
+		if err != nil {
+			log.Fatalf("Error reading URLs from %s: %v", source, err)
+		}
+		hunters = append(hunters, dlh)
+	}
+	if len(args) > 0 {
+		hunters = append(hunters, webscraper.NewListHunter("command line", args))
+	}
+	hunt(cfg, hunters)
+}
@@ -2,68 +2,103 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"strings"
-	"time"
 
-	"github.com/yingtu35/dead-link-hunter/internal/export"
-	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+	"github.com/yingtu35/dead-link-hunter/internal/config"
 )
 
+// command is a subcommand of the CLI
+type command struct {
+	name  string
+	usage string // The arguments of the command
+	short string // A one line description of the command
+	run   func(cfg *config.Config, args []string)
+}
+
+var commands []command
+
+// The commands refer to the list in their usage, so it is set at init
+func init() {
+	commands = []command{
+		{"crawl", "[flags]", "Crawl websites or Markdown files for dead links (default)", crawl},
+		{"check", "[flags] [url...]", "Check a list of URLs without crawling them", check},
+		{"render-diff", "[flags]", "Compare the links of pages with and without JavaScript rendering", renderDiff},
+		{"report", "[flags] <result.json>", "Re-render a saved JSON result in other formats", report},
+		{"diff", "[flags] <old.json> <new.json>", "Compare two saved JSON results", diff},
+		{"serve", "[flags] <result.json>", "Serve a saved JSON result as an HTML report", serve},
+		{"validate-config", "[flags] <config file>", "Validate a config file and print the resulting configuration", validateConfig},
+	}
+}
+
 func main() {
 	log.SetFlags(0)
 
-	url := flag.String("url", "", "URL to fetch")
-	static := flag.Bool("static", false, "Enable static scraping")
-	exportType := flag.String("export", "", "Export file format (csv, json)")
-	filename := flag.String("filename", "result", "Export file name")
-	maxDepth := flag.Int("maxDepth", webscraper.MaxDepth, "Max depth to scrape")
-	maxConcurrency := flag.Int("maxConcurrency", webscraper.MaxConcurrency, "Max concurrency")
-	timeout := flag.Int("timeout", webscraper.DefaultTimeout, "Timeout for each request")
-
-	flag.Parse()
+	// Without a subcommand the flags are those of crawl
+	name, args := "crawl", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	if *url == "" {
-		flag.Usage()
-		os.Exit(1)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if cmd.name == "validate-config" && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				args = append([]string{"-config", args[0]}, args[1:]...)
+			}
+			cfg, rest := parseConfig(cmd, args)
+			cmd.run(cfg, rest)
+			return
+		}
 	}
 
-	// Get all dead links
-	var dlh webscraper.WebScraper
-	if *static {
-		dlh = webscraper.NewStaticHunter(*url)
-	} else {
-		dlh = webscraper.NewDynamicHunter(*url)
+	log.Printf("Unknown command %q", name)
+	printCommands()
+	os.Exit(1)
+}
+
+// parseConfig parses the options shared by every command and returns them
+// with the remaining arguments. Invalid options exit the program.
+func parseConfig(cmd command, args []string) (*config.Config, []string) {
+	fs := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	fs.Usage = func() {
+		log.Printf("Usage: %s %s %s\n\n%s\n\nFlags:", os.Args[0], cmd.name, cmd.usage, cmd.short)
+		fs.PrintDefaults()
+		log.Println()
+		printCommands()
 	}
 
-	var options = &webscraper.ScraperOptions{
-		MaxDepth:       *maxDepth,
-		MaxConcurrency: *maxConcurrency,
-		Timeout:        *timeout,
+	cfg, err := config.Parse(fs, args)
+	if err != nil {
+		log.Printf("Error reading configuration: %v", err)
+		fs.Usage()
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Printf("Invalid configuration:\n%v", err)
+		os.Exit(1)
 	}
-	dlh.SetHunterOptions(options)
+	return cfg, fs.Args()
+}
 
-	start := time.Now()
-	dlh.StartHunting()
-	elapsed := time.Since(start)
+// printCommands prints the list of commands
+func printCommands() {
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-16s %s\n", cmd.name, cmd.short)
+	}
+	log.Print(b.String())
+}
 
-	var exporter export.Exporter
-	switch strings.ToLower(*exportType) {
-	case "csv":
-		exporter = export.NewCSVExporter()
-		if err := exporter.Export(dlh.GetResults(), *filename); err != nil {
-			log.Fatalf("Error exporting data: %v", err)
+// usageError reports wrong arguments of a command and exits
+func usageError(cmd string, format string, v ...interface{}) {
+	log.Printf(format, v...)
+	for _, c := range commands {
+		if c.name == cmd {
+			log.Printf("Usage: %s %s %s", os.Args[0], c.name, c.usage)
 		}
-	case "json":
-		exporter = export.NewJsonExporter()
-		if err := exporter.Export(dlh.GetResults(), *filename); err != nil {
-			log.Fatalf("Error exporting data: %v", err)
-		}
-	default:
-		// Print the results
-		dlh.PrintResults()
 	}
-
-	log.Printf("Total Hunting Time: %s", elapsed)
+	os.Exit(1)
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+	"github.com/yingtu35/dead-link-hunter/internal/export"
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// renderDiff compares the links of the pages of the seeds with and without JavaScript
+func renderDiff(cfg *config.Config, args []string) {
+	if len(args) > 0 {
+		usageError("render-diff", "Unexpected arguments %v", args)
+	}
+	if len(cfg.Seeds) == 0 {
+		usageError("render-diff", "A seed URL is required")
+	}
+	for _, e := range cfg.Exports {
+		if strings.ToLower(e.Format) != "json" {
+			usageError("render-diff", "Only json exports are supported, got %s", e.Format)
+		}
+	}
+	options, err := cfg.ScraperOptions()
+	if err != nil {
+		log.Fatalf("Error reading configuration: %v", err)
+	}
+
+	start := time.Now()
+	var diffs []webscraper.RenderDiff
+	compared := make(map[string]bool)
+	for _, seed := range cfg.Seeds {
+		differ := webscraper.NewRenderDiffer(seed)
+		differ.SetOptions(options)
+		// Pages reachable from several seeds are listed once
+		for _, diff := range differ.Start() {
+			if !compared[diff.Page] {
+				compared[diff.Page] = true
+				diffs = append(diffs, diff)
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	outputs, closeOutputs := openOutputs(cfg)
+	defer closeOutputs()
+	for _, out := range outputs {
+		if err := export.ExportRenderDiffs(diffs, out.w); err != nil {
+			log.Fatalf("Error exporting data: %v", err)
+		}
+	}
+	if len(outputs) == 0 {
+		webscraper.PrintRenderDiffs(diffs)
+	}
+	log.Printf("Total Comparison Time: %s", elapsed)
+}
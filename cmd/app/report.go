@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/baseline"
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+	"github.com/yingtu35/dead-link-hunter/internal/suppression"
+)
+
+// report renders a saved result again, e.g. a JSON result as HTML
+func report(cfg *config.Config, args []string) {
+	if len(args) != 1 {
+		usageError("report", "Expected one result file, got %d", len(args))
+	}
+	results := readResults(args[0])
+	if rules := readSuppressions(cfg); rules != nil {
+		suppressed := suppression.Apply(rules, results, time.Now())
+		log.Printf("Suppressed %d dead links", suppressed)
+	}
+
+	outputs, closeOutputs := openOutputs(cfg)
+	defer closeOutputs()
+	writeResults(cfg, outputs, results, nil, false)
+}
+
+// diff compares two saved results, failing if the newer one has new dead links
+func diff(cfg *config.Config, args []string) {
+	if len(args) != 2 {
+		usageError("diff", "Expected two result files, got %d", len(args))
+	}
+	old, current := readResults(args[0]), readResults(args[1])
+
+	d := baseline.Compare(old, current)
+	log.Printf("%d new, %d still broken, %d fixed dead links", baseline.Count(d.New), baseline.Count(d.Existing), baseline.Count(d.Fixed))
+
+	outputs, closeOutputs := openOutputs(cfg)
+	writeResults(cfg, outputs, baseline.Tag(current, d), d, false)
+	closeOutputs()
+	exitStatus(cfg, current, d)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/baseline"
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+	"github.com/yingtu35/dead-link-hunter/internal/export"
+	"github.com/yingtu35/dead-link-hunter/internal/suppression"
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// output is an export of the run and where it is written
+type output struct {
+	exporter export.Exporter
+	w        io.Writer
+}
+
+// openOutputs creates the exporters of the configuration and opens their
+// outputs. The returned function closes the files.
+func openOutputs(cfg *config.Config) ([]output, func()) {
+	view := export.View(strings.ToLower(cfg.GroupBy))
+	var outputs []output
+	var files []*os.File
+	for _, e := range cfg.Exports {
+		exporter, err := export.New(e.Format, view)
+		if err != nil {
+			log.Fatalf("Error creating exporter: %v", err)
+		}
+		if e.Output == "-" {
+			outputs = append(outputs, output{exporter, os.Stdout})
+			continue
+		}
+		path := e.Output
+		if path == "" {
+			path = cfg.Filename + "." + export.FileExtension(e.Format)
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("Error creating file %s: %v", path, err)
+		}
+		files = append(files, file)
+		outputs = append(outputs, output{exporter, file})
+	}
+	return outputs, func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+}
+
+// readResults reads a JSON export grouped by page
+func readResults(path string) map[string]*webscraper.Page {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", path, err)
+	}
+	defer file.Close()
+
+	results, err := export.ReadJSON(file)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", path, err)
+	}
+	return results
+}
+
+// readSuppressions reads the suppression file of the configuration, if any
+func readSuppressions(cfg *config.Config) []*suppression.Rule {
+	if cfg.Suppressions == "" {
+		return nil
+	}
+	rules, err := suppression.Load(cfg.Suppressions)
+	if err != nil {
+		log.Fatalf("Error reading suppressions %s: %v", cfg.Suppressions, err)
+	}
+	return rules
+}
+
+// hunt runs the hunters with the options of the configuration, then reports
+// the merged results like every other command
+func hunt(cfg *config.Config, hunters []webscraper.WebScraper) {
+	// Read the baseline before hunting so a bad file fails fast
+	var base map[string]*webscraper.Page
+	if cfg.Baseline != "" {
+		base = readResults(cfg.Baseline)
+	}
+	rules := readSuppressions(cfg)
+
+	// Open the outputs before hunting so results can be streamed into them
+	outputs, closeOutputs := openOutputs(cfg)
+
+	start := time.Now()
+	options, err := cfg.ScraperOptions()
+	if err != nil {
+		log.Fatalf("Error loading options: %v", err)
+	}
+	// With a baseline the results are only known once the hunt is over
+	var streams []func(page string, deadLink webscraper.DeadLink)
+	var remaining []output
+	for _, out := range outputs {
+		if streamer, ok := out.exporter.(export.StreamExporter); ok && base == nil {
+			streams = append(streams, streamer.Stream(out.w))
+		} else {
+			remaining = append(remaining, out)
+		}
+	}
+	if len(streams) > 0 {
+		options.OnDeadLink = func(page string, deadLink webscraper.DeadLink) {
+			deadLink.Suppression = suppression.Match(rules, page, deadLink, start)
+			for _, stream := range streams {
+				stream(page, deadLink)
+			}
+		}
+	}
+
+	results := make(map[string]*webscraper.Page)
+	for _, dlh := range hunters {
+		dlh.SetHunterOptions(options)
+		dlh.StartHunting()
+		// Pages reachable from several seeds keep the results of the first
+		for url, page := range *dlh.GetResults() {
+			if _, ok := results[url]; !ok {
+				results[url] = page
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	if rules != nil {
+		suppressed := suppression.Apply(rules, results, start)
+		log.Printf("Suppressed %d dead links", suppressed)
+	}
+
+	var diff *baseline.Diff
+	if base != nil {
+		diff = baseline.Compare(base, results)
+		log.Printf("Baseline: %d new, %d still broken, %d fixed dead links", baseline.Count(diff.New), baseline.Count(diff.Existing), baseline.Count(diff.Fixed))
+		// Exports keep every dead link so they can be the baseline of the next run
+		results = baseline.Tag(results, diff)
+	}
+
+	writeResults(cfg, remaining, results, diff, len(streams) > 0)
+	log.Printf("Total Hunting Time: %s", elapsed)
+	closeOutputs()
+	exitStatus(cfg, results, diff)
+}
+
+// writeResults exports the results, or prints them if nothing is exported.
+// A diff is printed instead of the results it was computed from.
+func writeResults(cfg *config.Config, outputs []output, results map[string]*webscraper.Page, diff *baseline.Diff, streamed bool) {
+	for _, out := range outputs {
+		if err := out.exporter.Export(&results, out.w); err != nil {
+			log.Fatalf("Error exporting data: %v", err)
+		}
+	}
+	switch {
+	case len(outputs) > 0 || streamed:
+		// The results were exported or streamed
+	case diff != nil:
+		baseline.PrintDiff(diff)
+	case export.View(strings.ToLower(cfg.GroupBy)) == export.ViewTarget:
+		webscraper.PrintTargets(results)
+	default:
+		webscraper.PrintPages(results)
+	}
+}
+
+// exitStatus exits with status 1 if there are new dead links against a
+// baseline, or without one, more dead links than the configured maximum
+func exitStatus(cfg *config.Config, results map[string]*webscraper.Page, diff *baseline.Diff) {
+	// Only new dead links fail a run against a baseline
+	if diff != nil {
+		if baseline.Count(diff.New) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	if count := baseline.Count(results); cfg.MaxDeadLinks >= 0 && count > cfg.MaxDeadLinks {
+		log.Printf("%d dead links exceed the maximum of %d", count, cfg.MaxDeadLinks)
+		os.Exit(1)
+	}
+}
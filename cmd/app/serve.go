@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+	"github.com/yingtu35/dead-link-hunter/internal/export"
+)
+
+// contentTypes are the content types of the formats served besides HTML
+var contentTypes = map[string]string{
+	"csv":      "text/csv; charset=utf-8",
+	"json":     "application/json",
+	"ndjson":   "application/x-ndjson",
+	"sarif":    "application/sarif+json",
+	"junit":    "application/xml",
+	"markdown": "text/markdown; charset=utf-8",
+}
+
+// serve serves a saved result as an HTML report, or in the format of the
+// format query parameter. The file is read on every request so a result
+// overwritten by a later run is served without a restart.
+func serve(cfg *config.Config, args []string) {
+	if len(args) != 1 {
+		usageError("serve", "Expected one result file, got %d", len(args))
+	}
+	path := args[0]
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("Error opening %s: %v", path, err)
+	}
+	view := export.View(strings.ToLower(cfg.GroupBy))
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "html"
+		}
+		exporter, err := export.New(format, view)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		results, err := export.ReadJSON(file)
+		file.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		contentType, ok := contentTypes[strings.ToLower(format)]
+		if !ok {
+			contentType = "text/html; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", contentType)
+		if err := exporter.Export(&results, w); err != nil {
+			log.Printf("Error serving %s: %v", path, err)
+		}
+	})
+
+	log.Printf("Serving %s on %s", path, cfg.Addr)
+	log.Fatal(http.ListenAndServe(cfg.Addr, nil))
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/yingtu35/dead-link-hunter/internal/config"
+)
+
+// validateConfig prints the configuration resulting from a config file, the
+// environment and flags, without secrets. Invalid configurations never get here.
+func validateConfig(cfg *config.Config, args []string) {
+	if len(args) > 0 {
+		usageError("validate-config", "Unexpected arguments %v", args)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		log.Fatalf("Error printing configuration: %v", err)
+	}
+	log.Println("Configuration is valid")
+}
@@ -4,14 +4,16 @@ go 1.23.0
 
 toolchain go1.23.7
 
-require golang.org/x/net v0.37.0
+require (
+	github.com/gocarina/gocsv v0.0.0-20240520201108-78e41c74b4b1
+	github.com/playwright-community/playwright-go v0.5001.0
+	github.com/rodaine/table v1.3.0
+	golang.org/x/net v0.37.0
+	golang.org/x/sync v0.12.0
+)
 
 require (
 	github.com/deckarep/golang-set/v2 v2.8.0 // indirect
 	github.com/go-jose/go-jose/v3 v3.0.4 // indirect
 	github.com/go-stack/stack v1.8.1 // indirect
-	github.com/gocarina/gocsv v0.0.0-20240520201108-78e41c74b4b1 // indirect
-	github.com/playwright-community/playwright-go v0.5001.0 // indirect
-	github.com/rodaine/table v1.3.0 // indirect
-	golang.org/x/sync v0.12.0 // indirect
 )
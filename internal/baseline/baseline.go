@@ -0,0 +1,143 @@
+package baseline
+
+import (
+	"log"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// Diff is the result of comparing dead links against a baseline. A dead link
+// is identified by its page and URL so it still matches when its line moves.
+type Diff struct {
+	New      map[string]*webscraper.Page // Dead links that are not in the baseline
+	Existing map[string]*webscraper.Page // Dead links that are still broken since the baseline
+	Fixed    map[string]*webscraper.Page // Dead links of the baseline that are no longer found
+}
+
+// Compare classifies the dead links of current against the baseline. The
+// fixed dead links a baseline was exported with are not part of it.
+func Compare(baseline, current map[string]*webscraper.Page) *Diff {
+	diff := &Diff{
+		New:      make(map[string]*webscraper.Page),
+		Existing: make(map[string]*webscraper.Page),
+		Fixed:    make(map[string]*webscraper.Page),
+	}
+
+	for url, page := range current {
+		// A link can be dead more than once on a page, count the occurrences
+		remaining := make(map[string]int)
+		if old, ok := baseline[url]; ok {
+			for _, deadLink := range old.DeadLinks {
+				remaining[deadLink.URL]++
+			}
+		}
+		for _, deadLink := range page.DeadLinks {
+			if remaining[deadLink.URL] > 0 {
+				remaining[deadLink.URL]--
+				add(diff.Existing, url, deadLink, webscraper.BaselineExisting)
+			} else {
+				add(diff.New, url, deadLink, webscraper.BaselineNew)
+			}
+		}
+	}
+
+	for url, page := range baseline {
+		// Every occurrence still found was matched above
+		found := make(map[string]int)
+		if cur, ok := current[url]; ok {
+			for _, deadLink := range cur.DeadLinks {
+				found[deadLink.URL]++
+			}
+		}
+		for _, deadLink := range page.DeadLinks {
+			if found[deadLink.URL] > 0 {
+				found[deadLink.URL]--
+			} else {
+				add(diff.Fixed, url, deadLink, webscraper.BaselineFixed)
+			}
+		}
+	}
+
+	// Suppressed dead links, console messages and screenshots are kept with the new dead links so they are still listed
+	for url, page := range current {
+		if len(page.Suppressed) == 0 && len(page.ConsoleMessages) == 0 && page.Screenshot == "" {
+			continue
+		}
+		if _, ok := diff.New[url]; !ok {
+			diff.New[url] = &webscraper.Page{DeadLinks: []webscraper.DeadLink{}, CheckedLinks: []string{}}
+		}
+		diff.New[url].Suppressed = page.Suppressed
+		diff.New[url].ConsoleMessages = page.ConsoleMessages
+		diff.New[url].Screenshot = page.Screenshot
+	}
+	return diff
+}
+
+// Tag returns a copy of the pages of current the diff was computed from, with
+// every dead link tagged new or existing and the fixed dead links of the
+// baseline listed in Fixed of their page
+func Tag(current map[string]*webscraper.Page, diff *Diff) map[string]*webscraper.Page {
+	tagged := make(map[string]*webscraper.Page, len(current))
+	for url, page := range current {
+		// Compare matched the first occurrences of a link as still broken
+		existing := make(map[string]int)
+		if old, ok := diff.Existing[url]; ok {
+			for _, deadLink := range old.DeadLinks {
+				existing[deadLink.URL]++
+			}
+		}
+		copied := *page
+		copied.DeadLinks = make([]webscraper.DeadLink, 0, len(page.DeadLinks))
+		copied.Fixed = nil
+		for _, deadLink := range page.DeadLinks {
+			deadLink.Baseline = webscraper.BaselineNew
+			if existing[deadLink.URL] > 0 {
+				existing[deadLink.URL]--
+				deadLink.Baseline = webscraper.BaselineExisting
+			}
+			copied.DeadLinks = append(copied.DeadLinks, deadLink)
+		}
+		tagged[url] = &copied
+	}
+
+	for url, page := range diff.Fixed {
+		if _, ok := tagged[url]; !ok {
+			tagged[url] = &webscraper.Page{DeadLinks: []webscraper.DeadLink{}, CheckedLinks: []string{}}
+		}
+		tagged[url].Fixed = append(tagged[url].Fixed, page.DeadLinks...)
+	}
+	return tagged
+}
+
+// Count returns the number of dead links in pages
+func Count(pages map[string]*webscraper.Page) int {
+	count := 0
+	for _, page := range pages {
+		count += page.DeadLinkCount
+	}
+	return count
+}
+
+// PrintDiff prints the new, still broken and fixed dead links as tables
+func PrintDiff(diff *Diff) {
+	log.Println()
+	log.Printf("New dead links: %d", Count(diff.New))
+	webscraper.PrintPages(diff.New)
+	log.Println()
+	log.Printf("Still broken dead links: %d", Count(diff.Existing))
+	webscraper.PrintPages(diff.Existing)
+	log.Println()
+	log.Printf("Fixed dead links: %d", Count(diff.Fixed))
+	webscraper.PrintPages(diff.Fixed)
+}
+
+func add(pages map[string]*webscraper.Page, url string, deadLink webscraper.DeadLink, state webscraper.BaselineState) {
+	page, ok := pages[url]
+	if !ok {
+		page = &webscraper.Page{DeadLinks: []webscraper.DeadLink{}, CheckedLinks: []string{}}
+		pages[url] = page
+	}
+	deadLink.Baseline = state
+	page.DeadLinkCount++
+	page.DeadLinks = append(page.DeadLinks, deadLink)
+}
@@ -0,0 +1,162 @@
+package baseline
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// pages builds results from page and dead link URL pairs
+func pages(pairs ...string) map[string]*webscraper.Page {
+	result := make(map[string]*webscraper.Page)
+	for i := 0; i < len(pairs); i += 2 {
+		page, ok := result[pairs[i]]
+		if !ok {
+			page = &webscraper.Page{DeadLinks: []webscraper.DeadLink{}, CheckedLinks: []string{}}
+			result[pairs[i]] = page
+		}
+		page.DeadLinkCount++
+		page.DeadLinks = append(page.DeadLinks, webscraper.DeadLink{URL: pairs[i+1], Line: i + 1})
+	}
+	return result
+}
+
+// flatten returns the dead links of pages as sorted "page link" strings
+func flatten(pages map[string]*webscraper.Page) []string {
+	var result []string
+	for url, page := range pages {
+		for _, deadLink := range page.DeadLinks {
+			result = append(result, url+" "+deadLink.URL)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// flattenTagged returns the dead links of tagged pages as sorted "state page link" strings
+func flattenTagged(pages map[string]*webscraper.Page) []string {
+	var result []string
+	for url, page := range pages {
+		for _, deadLink := range page.DeadLinks {
+			result = append(result, string(deadLink.Baseline)+" "+url+" "+deadLink.URL)
+		}
+		for _, deadLink := range page.Fixed {
+			result = append(result, string(deadLink.Baseline)+" "+url+" "+deadLink.URL)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name         string
+		baseline     map[string]*webscraper.Page
+		current      map[string]*webscraper.Page
+		wantNew      []string
+		wantExisting []string
+		wantFixed    []string
+		wantTagged   []string
+	}{
+		{
+			name:       "empty baseline",
+			baseline:   pages(),
+			current:    pages("/a", "/x", "/b", "/y"),
+			wantNew:    []string{"/a /x", "/b /y"},
+			wantTagged: []string{"new /a /x", "new /b /y"},
+		},
+		{
+			name:       "nothing found",
+			baseline:   pages("/a", "/x"),
+			current:    pages(),
+			wantFixed:  []string{"/a /x"},
+			wantTagged: []string{"fixed /a /x"},
+		},
+		{
+			name:         "unchanged",
+			baseline:     pages("/a", "/x", "/a", "/y"),
+			current:      pages("/a", "/y", "/a", "/x"),
+			wantExisting: []string{"/a /x", "/a /y"},
+			wantTagged:   []string{"existing /a /x", "existing /a /y"},
+		},
+		{
+			name:         "new and fixed",
+			baseline:     pages("/a", "/x", "/a", "/y"),
+			current:      pages("/a", "/x", "/a", "/z"),
+			wantNew:      []string{"/a /z"},
+			wantExisting: []string{"/a /x"},
+			wantFixed:    []string{"/a /y"},
+			wantTagged:   []string{"existing /a /x", "fixed /a /y", "new /a /z"},
+		},
+		{
+			name:         "duplicate link added",
+			baseline:     pages("/a", "/x"),
+			current:      pages("/a", "/x", "/a", "/x"),
+			wantNew:      []string{"/a /x"},
+			wantExisting: []string{"/a /x"},
+			wantTagged:   []string{"existing /a /x", "new /a /x"},
+		},
+		{
+			name:         "duplicate link removed",
+			baseline:     pages("/a", "/x", "/a", "/x"),
+			current:      pages("/a", "/x"),
+			wantExisting: []string{"/a /x"},
+			wantFixed:    []string{"/a /x"},
+			wantTagged:   []string{"existing /a /x", "fixed /a /x"},
+		},
+		{
+			name:       "link moved to another page",
+			baseline:   pages("/a", "/x"),
+			current:    pages("/b", "/x"),
+			wantNew:    []string{"/b /x"},
+			wantFixed:  []string{"/a /x"},
+			wantTagged: []string{"fixed /a /x", "new /b /x"},
+		},
+	}
+	for _, tt := range tests {
+		diff := Compare(tt.baseline, tt.current)
+		if got := flatten(diff.New); !reflect.DeepEqual(got, tt.wantNew) {
+			t.Errorf("%s: new %v, want %v", tt.name, got, tt.wantNew)
+		}
+		if got := flatten(diff.Existing); !reflect.DeepEqual(got, tt.wantExisting) {
+			t.Errorf("%s: existing %v, want %v", tt.name, got, tt.wantExisting)
+		}
+		if got := flatten(diff.Fixed); !reflect.DeepEqual(got, tt.wantFixed) {
+			t.Errorf("%s: fixed %v, want %v", tt.name, got, tt.wantFixed)
+		}
+		if got := Count(diff.New); got != len(tt.wantNew) {
+			t.Errorf("%s: Count(new) = %d, want %d", tt.name, got, len(tt.wantNew))
+		}
+
+		tagged := Tag(tt.current, diff)
+		if got := flattenTagged(tagged); !reflect.DeepEqual(got, tt.wantTagged) {
+			t.Errorf("%s: tagged %v, want %v", tt.name, got, tt.wantTagged)
+		}
+		// Fixed dead links aren't counted and the current results are left as they are
+		if got, want := Count(tagged), Count(tt.current); got != want {
+			t.Errorf("%s: tagged count %d, want %d", tt.name, got, want)
+		}
+		for _, page := range tt.current {
+			for _, deadLink := range page.DeadLinks {
+				if deadLink.Baseline != "" {
+					t.Errorf("%s: current dead link %s was tagged", tt.name, deadLink.URL)
+				}
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresFixedOfBaseline(t *testing.T) {
+	// A tagged export used as the next baseline must not resurrect its fixed links
+	base := pages("/a", "/x")
+	base["/a"].Fixed = []webscraper.DeadLink{{URL: "/y", Baseline: webscraper.BaselineFixed}}
+	diff := Compare(base, pages("/a", "/x", "/a", "/y"))
+	if got, want := flatten(diff.New), []string{"/a /y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("new %v, want %v", got, want)
+	}
+	if got := flatten(diff.Fixed); got != nil {
+		t.Errorf("fixed %v, want none", got)
+	}
+}
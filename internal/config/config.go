@@ -0,0 +1,639 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/yingtu35/dead-link-hunter/internal/export"
+	"github.com/yingtu35/dead-link-hunter/internal/suppression"
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+	"github.com/yingtu35/dead-link-hunter/pkg/domain"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the
+// configuration, e.g. DLH_MAX_DEPTH overrides maxDepth
+const EnvPrefix = "DLH_"
+
+// Export is an export written at the end of a run
+type Export struct {
+	Format string `json:"format"`           // One of export.Formats
+	Output string `json:"output,omitempty"` // The output path, - for stdout, default <filename>.<extension>
+}
+
+// Auth holds the credentials of the hosts matching a pattern. Secrets can be
+// read from environment variables so they don't have to be in the file.
+type Auth struct {
+	Host        string `json:"host"`                  // Glob pattern of host names, e.g. *.example.com
+	Username    string `json:"username,omitempty"`    // Basic auth user name
+	Password    string `json:"password,omitempty"`    // Basic auth password
+	PasswordEnv string `json:"passwordEnv,omitempty"` // Environment variable of the basic auth password
+	Token       string `json:"token,omitempty"`       // Bearer token, sent instead of basic auth if set
+	TokenEnv    string `json:"tokenEnv,omitempty"`    // Environment variable of the bearer token
+}
+
+// Login is a form-based login run before crawling in dynamic mode
+type Login struct {
+	URL          string      `json:"url"`                    // The login page
+	Steps        []LoginStep `json:"steps"`                  // The steps run on the login page, in order
+	WaitFor      string      `json:"waitFor,omitempty"`      // Selector of an element that only appears once logged in
+	WaitForURL   string      `json:"waitForURL,omitempty"`   // Glob pattern of the URL reached once logged in
+	StorageState string      `json:"storageState,omitempty"` // File the session is saved to
+}
+
+// Wait is how dynamic pages are waited for before their links are extracted
+type Wait struct {
+	Pattern  string `json:"pattern,omitempty"`  // Glob pattern of the pages it applies to, only in pageWaits
+	Until    string `json:"until,omitempty"`    // The load state waited for: load, domcontentloaded or networkidle
+	Selector string `json:"selector,omitempty"` // Selector of an element waited for once loaded
+	Delay    string `json:"delay,omitempty"`    // Fixed delay once loaded, e.g. 500ms
+}
+
+// Expand loads the content of dynamic pages that appears on scroll or on click
+type Expand struct {
+	Scrolls int    `json:"scrolls,omitempty"` // Maximum scrolls to the bottom, stopping once the page stops growing
+	Click   string `json:"click,omitempty"`   // Selector of a "load more" button
+	Clicks  int    `json:"clicks,omitempty"`  // Maximum clicks of the button
+}
+
+// Render decides which pages are rendered by the browser in hybrid mode
+type Render struct {
+	Patterns []string `json:"patterns,omitempty"` // Glob patterns of the pages that are always rendered
+	MinLinks int      `json:"minLinks"`           // Pages with fewer links are rendered, 0 disables the check
+	Markers  bool     `json:"markers"`            // Render pages with an empty application root or a <noscript> warning
+}
+
+// Browser configures the browser contexts of dynamic mode
+type Browser struct {
+	Block          []string `json:"block,omitempty"`      // Resource types that are not loaded, e.g. image
+	BlockHosts     []string `json:"blockHosts,omitempty"` // Glob patterns of hosts that are not loaded
+	BlockAnalytics bool     `json:"blockAnalytics"`       // Don't load common analytics and tag manager hosts
+	Device         string   `json:"device,omitempty"`     // Playwright device name, e.g. iPhone 13
+	Viewport       string   `json:"viewport,omitempty"`   // Viewport size as WIDTHxHEIGHT, e.g. 390x844
+	UserAgent      string   `json:"userAgent,omitempty"`  // User agent of the browser
+}
+
+// LoginStep fills an input or clicks an element of the login page. Values
+// such as credentials can be read from environment variables.
+type LoginStep struct {
+	Fill     string `json:"fill,omitempty"`     // Selector of an input to fill
+	Value    string `json:"value,omitempty"`    // The value to fill
+	ValueEnv string `json:"valueEnv,omitempty"` // Environment variable of the value to fill
+	Click    string `json:"click,omitempty"`    // Selector of an element to click
+}
+
+// Config holds every option of a run. Values are read from a JSON file, then
+// overridden by environment variables and finally by command-line flags.
+type Config struct {
+	Path string `json:"-"` // The config file, empty if there is none
+
+	Seeds          []string `json:"seeds"`                   // The websites to crawl
+	Markdown       string   `json:"markdown,omitempty"`      // A Markdown file or directory to check instead of websites
+	Static         bool     `json:"static"`                  // Crawl without rendering JavaScript
+	Hybrid         bool     `json:"hybrid"`                  // Crawl without rendering JavaScript, except the pages matching render
+	Render         Render   `json:"render"`                  // Which pages are rendered in hybrid mode
+	MaxDepth       int      `json:"maxDepth"`                // The maximum crawl depth from a seed
+	MaxConcurrency int      `json:"maxConcurrency"`          // The maximum number of concurrent requests
+	Timeout        int      `json:"timeout"`                 // The request timeout in seconds
+	ContextReuse   int      `json:"contextReuse"`            // Navigations of a browser context before it is replaced
+	Exclude        []string `json:"exclude,omitempty"`       // Glob patterns of links that are not checked
+	Scope          []string `json:"scope,omitempty"`         // Glob patterns of the pages crawled besides the seeds, every page of their domain if empty
+	AliveStatuses  []int    `json:"aliveStatuses,omitempty"` // Status codes above 299 that are not dead links
+	Input          string   `json:"input,omitempty"`         // A file of URLs for the check command, - for stdin
+
+	Headers map[string]string `json:"headers,omitempty"` // Headers sent with every request
+	Cookies string            `json:"cookies,omitempty"` // A Netscape or JSON cookie file
+	Auth    []Auth            `json:"auth,omitempty"`    // Credentials sent to the hosts they match
+	Login   *Login            `json:"login,omitempty"`   // A login run before crawling in dynamic mode
+
+	Wait      Wait   `json:"wait"`                // How dynamic pages are waited for
+	PageWaits []Wait `json:"pageWaits,omitempty"` // How the pages matching patterns are waited for instead, first match wins
+	Expand    Expand `json:"expand"`              // How content loaded on scroll or on click is loaded before extracting links
+
+	Screenshots string  `json:"screenshots,omitempty"` // The directory screenshots of dynamic pages with dead links are saved to
+	Browser     Browser `json:"browser"`               // Resource blocking and device emulation of dynamic mode
+
+	Exports      []Export `json:"exports,omitempty"`      // The exports to write, the results are printed if there are none
+	Filename     string   `json:"filename"`               // The name of exports without an output path
+	GroupBy      string   `json:"groupBy"`                // Group the results by page or by target
+	Baseline     string   `json:"baseline,omitempty"`     // A JSON export of a previous run, dead links are tagged new, existing or fixed against it
+	Suppressions string   `json:"suppressions,omitempty"` // A file of acknowledged dead links
+	MaxDeadLinks int      `json:"maxDeadLinks"`           // The run fails with more dead links than this, -1 never fails
+	Addr         string   `json:"addr"`                   // The address the serve command listens on
+
+	exportFormat string // The -export flag, replaces the exports of the file
+	exportOutput string // The -output flag
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	return &Config{
+		MaxDepth:       webscraper.MaxDepth,
+		MaxConcurrency: webscraper.MaxConcurrency,
+		Timeout:        webscraper.DefaultTimeout,
+		ContextReuse:   webscraper.ContextReuse,
+		Wait:           Wait{Until: webscraper.WaitLoad},
+		Expand:         Expand{Clicks: webscraper.LoadMoreClicks},
+		Render:         Render{MinLinks: webscraper.RenderMinLinks, Markers: true},
+		Filename:       "result",
+		GroupBy:        string(export.ViewPage),
+		MaxDeadLinks:   -1,
+		Addr:           ":8080",
+	}
+}
+
+// Parse builds the configuration from the config file named by the -config
+// flag, the environment and args, in increasing priority. Parsing errors are
+// handled according to the error handling of fs.
+func Parse(fs *flag.FlagSet, args []string) (*Config, error) {
+	// The first pass only finds the config file, whose values become the
+	// defaults of the second pass
+	first := Default()
+	first.bindFlags(fs)
+	if err := applyEnv(fs); err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	c := Default()
+	if first.Path != "" {
+		if err := c.Load(first.Path); err != nil {
+			return nil, fmt.Errorf("%s: %w", first.Path, err)
+		}
+	}
+
+	second := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	second.SetOutput(io.Discard)
+	c.bindFlags(second)
+	if err := applyEnv(second); err != nil {
+		return nil, err
+	}
+	if err := second.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if c.exportFormat != "" {
+		c.Exports = []Export{{Format: c.exportFormat, Output: c.exportOutput}}
+	} else if c.exportOutput != "" {
+		// -output can't tell which of several exports it is meant for
+		if len(c.Exports) != 1 {
+			return nil, fmt.Errorf("-output needs -export or exactly one export in the config file, got %d exports", len(c.Exports))
+		}
+		c.Exports[0].Output = c.exportOutput
+	}
+	return c, nil
+}
+
+// Load reads a JSON config file over the current values. Unknown keys are
+// errors so that typos don't go unnoticed.
+func (c *Config) Load(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return err
+	}
+	c.Path = path
+	return nil
+}
+
+// Validate returns every problem of the configuration joined into one error,
+// or nil. Seeds are only required by commands that crawl, see RequireSeeds.
+func (c *Config) Validate() error {
+	var errs []error
+	for _, seed := range c.Seeds {
+		if _, err := domain.GetDomain(seed); err != nil {
+			errs = append(errs, fmt.Errorf("invalid seed %q: %w", seed, err))
+		}
+	}
+	if c.MaxDepth < 0 {
+		errs = append(errs, fmt.Errorf("maxDepth must not be negative, got %d", c.MaxDepth))
+	}
+	if c.MaxConcurrency < 1 {
+		errs = append(errs, fmt.Errorf("maxConcurrency must be at least 1, got %d", c.MaxConcurrency))
+	}
+	if c.Timeout < 1 {
+		errs = append(errs, fmt.Errorf("timeout must be at least 1 second, got %d", c.Timeout))
+	}
+	if c.ContextReuse < 1 {
+		errs = append(errs, fmt.Errorf("contextReuse must be at least 1, got %d", c.ContextReuse))
+	}
+	for _, status := range c.AliveStatuses {
+		if status < 300 || status > 599 {
+			errs = append(errs, fmt.Errorf("alive status %d is not between 300 and 599", status))
+		}
+	}
+	view := export.View(strings.ToLower(c.GroupBy))
+	if view != export.ViewPage && view != export.ViewTarget {
+		errs = append(errs, fmt.Errorf("invalid groupBy value %q", c.GroupBy))
+	}
+	for _, e := range c.Exports {
+		if _, err := export.New(e.Format, view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Input != "" && c.Input != "-" {
+		if _, err := os.Stat(c.Input); err != nil {
+			errs = append(errs, fmt.Errorf("input: %w", err))
+		}
+	}
+	if c.Cookies != "" {
+		if _, err := webscraper.LoadCookies(c.Cookies); err != nil {
+			errs = append(errs, fmt.Errorf("cookies %s: %w", c.Cookies, err))
+		}
+	}
+	for _, auth := range c.Auth {
+		if auth.Host == "" {
+			errs = append(errs, errors.New("auth without a host"))
+		}
+		if auth.Username == "" && auth.Token == "" && auth.TokenEnv == "" {
+			errs = append(errs, fmt.Errorf("auth of %s needs a username or a token", auth.Host))
+		}
+		for _, env := range []string{auth.PasswordEnv, auth.TokenEnv} {
+			if _, ok := os.LookupEnv(env); env != "" && !ok {
+				errs = append(errs, fmt.Errorf("auth of %s: environment variable %s is not set", auth.Host, env))
+			}
+		}
+	}
+	if c.Login != nil {
+		errs = append(errs, c.Login.validate()...)
+	}
+	for _, resourceType := range c.Browser.Block {
+		if !slices.Contains(webscraper.ResourceTypes, resourceType) {
+			errs = append(errs, fmt.Errorf("invalid resource type %q, expected one of %s", resourceType, strings.Join(webscraper.ResourceTypes, ", ")))
+		}
+	}
+	if _, _, err := c.Browser.viewport(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Static && c.Hybrid {
+		errs = append(errs, errors.New("static and hybrid can't both be set"))
+	}
+	if c.Render.MinLinks < 0 {
+		errs = append(errs, fmt.Errorf("render minLinks must not be negative, got %d", c.Render.MinLinks))
+	}
+	if c.Expand.Scrolls < 0 {
+		errs = append(errs, fmt.Errorf("expand scrolls must not be negative, got %d", c.Expand.Scrolls))
+	}
+	if c.Expand.Click != "" && c.Expand.Clicks < 1 {
+		errs = append(errs, fmt.Errorf("expand clicks must be at least 1, got %d", c.Expand.Clicks))
+	}
+	if c.Wait.Pattern != "" {
+		errs = append(errs, errors.New("wait applies to every page, use pageWaits for patterns"))
+	}
+	errs = append(errs, c.Wait.validate("wait")...)
+	for i, wait := range c.PageWaits {
+		if wait.Pattern == "" {
+			errs = append(errs, fmt.Errorf("page wait %d needs a pattern", i+1))
+		}
+		errs = append(errs, wait.validate(fmt.Sprintf("page wait %d", i+1))...)
+	}
+	if c.Baseline != "" {
+		if _, err := os.Stat(c.Baseline); err != nil {
+			errs = append(errs, fmt.Errorf("baseline: %w", err))
+		}
+	}
+	if c.Suppressions != "" {
+		if _, err := suppression.Load(c.Suppressions); err != nil {
+			errs = append(errs, fmt.Errorf("suppressions %s: %w", c.Suppressions, err))
+		}
+	}
+	if c.MaxDeadLinks < -1 {
+		errs = append(errs, fmt.Errorf("maxDeadLinks must be -1 or more, got %d", c.MaxDeadLinks))
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the configuration without its secrets, for printing
+func (c *Config) Redacted() *Config {
+	const redacted = "<redacted>"
+	r := *c
+	if c.Login != nil {
+		login := *c.Login
+		login.Steps = make([]LoginStep, len(c.Login.Steps))
+		for i, step := range c.Login.Steps {
+			if step.Value != "" && step.Fill != "" {
+				step.Value = redacted
+			}
+			login.Steps[i] = step
+		}
+		r.Login = &login
+	}
+	r.Auth = make([]Auth, len(c.Auth))
+	for i, auth := range c.Auth {
+		if auth.Password != "" {
+			auth.Password = redacted
+		}
+		if auth.Token != "" {
+			auth.Token = redacted
+		}
+		r.Auth[i] = auth
+	}
+	if c.Headers != nil {
+		r.Headers = make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "Cookie") {
+				value = redacted
+			}
+			r.Headers[name] = value
+		}
+	}
+	return &r
+}
+
+// RequireSeeds returns an error if there is nothing to crawl
+func (c *Config) RequireSeeds() error {
+	if len(c.Seeds) == 0 && c.Markdown == "" {
+		return errors.New("a seed URL or a Markdown path is required")
+	}
+	return nil
+}
+
+// ScraperOptions returns the options of the hunters, with the cookies and
+// the secrets of the environment loaded
+func (c *Config) ScraperOptions() (*webscraper.ScraperOptions, error) {
+	options := &webscraper.ScraperOptions{
+		MaxDepth:       c.MaxDepth,
+		MaxConcurrency: c.MaxConcurrency,
+		Timeout:        c.Timeout,
+		ContextReuse:   c.ContextReuse,
+		Exclude:        c.Exclude,
+		Scope:          c.Scope,
+		AliveStatuses:  c.AliveStatuses,
+		Headers:        c.Headers,
+		Expand:         webscraper.Expansion{Scrolls: c.Expand.Scrolls, Click: c.Expand.Click, Clicks: c.Expand.Clicks},
+		Screenshots:    c.Screenshots,
+		Escalation:     webscraper.Escalation{Patterns: c.Render.Patterns, MinLinks: c.Render.MinLinks, Markers: c.Render.Markers},
+		Blocking:       webscraper.Blocking{ResourceTypes: c.Browser.Block, Hosts: c.Browser.BlockHosts, Analytics: c.Browser.BlockAnalytics},
+	}
+	width, height, err := c.Browser.viewport()
+	if err != nil {
+		return nil, err
+	}
+	options.Emulation = webscraper.Emulation{Device: c.Browser.Device, Width: width, Height: height, UserAgent: c.Browser.UserAgent}
+	if c.Cookies != "" {
+		cookies, err := webscraper.LoadCookies(c.Cookies)
+		if err != nil {
+			return nil, err
+		}
+		options.Cookies = cookies
+	}
+	for _, auth := range c.Auth {
+		hostAuth := webscraper.HostAuth{Host: auth.Host, Username: auth.Username, Password: auth.Password, Token: auth.Token}
+		if auth.PasswordEnv != "" {
+			hostAuth.Password = os.Getenv(auth.PasswordEnv)
+		}
+		if auth.TokenEnv != "" {
+			hostAuth.Token = os.Getenv(auth.TokenEnv)
+		}
+		options.Auth = append(options.Auth, hostAuth)
+	}
+	if c.Login != nil {
+		login := &webscraper.Login{
+			URL:          c.Login.URL,
+			WaitFor:      c.Login.WaitFor,
+			WaitForURL:   c.Login.WaitForURL,
+			StorageState: c.Login.StorageState,
+		}
+		for _, step := range c.Login.Steps {
+			value := step.Value
+			if step.ValueEnv != "" {
+				value = os.Getenv(step.ValueEnv)
+			}
+			login.Steps = append(login.Steps, webscraper.LoginStep{Fill: step.Fill, Value: value, Click: step.Click})
+		}
+		options.Login = login
+	}
+	// The page waits come first so they take precedence over the wait of every page
+	for _, wait := range append(c.PageWaits[:len(c.PageWaits):len(c.PageWaits)], c.Wait) {
+		strategy, err := wait.strategy()
+		if err != nil {
+			return nil, err
+		}
+		options.Wait = append(options.Wait, strategy)
+	}
+	return options, nil
+}
+
+// viewport returns the width and height of the viewport, 0 if it isn't set
+func (b Browser) viewport() (int, int, error) {
+	if b.Viewport == "" {
+		return 0, 0, nil
+	}
+	var width, height int
+	if n, err := fmt.Sscanf(b.Viewport, "%dx%d", &width, &height); err != nil || n != 2 || width < 1 || height < 1 {
+		return 0, 0, fmt.Errorf("invalid viewport %q, expected WIDTHxHEIGHT", b.Viewport)
+	}
+	return width, height, nil
+}
+
+// strategy returns the wait strategy of the hunters
+func (w Wait) strategy() (webscraper.WaitStrategy, error) {
+	strategy := webscraper.WaitStrategy{Pattern: w.Pattern, Until: w.Until, Selector: w.Selector}
+	if w.Delay != "" {
+		delay, err := time.ParseDuration(w.Delay)
+		if err != nil {
+			return strategy, err
+		}
+		strategy.Delay = delay
+	}
+	return strategy, nil
+}
+
+// validate returns the problems of the wait, named name in the errors
+func (w Wait) validate(name string) []error {
+	var errs []error
+	if w.Until != "" && !slices.Contains(webscraper.WaitStates, w.Until) {
+		errs = append(errs, fmt.Errorf("%s: invalid until value %q, expected one of %s", name, w.Until, strings.Join(webscraper.WaitStates, ", ")))
+	}
+	if w.Delay != "" {
+		if delay, err := time.ParseDuration(w.Delay); err != nil || delay < 0 {
+			errs = append(errs, fmt.Errorf("%s: invalid delay %q", name, w.Delay))
+		}
+	}
+	return errs
+}
+
+// validate returns the problems of the login
+func (l *Login) validate() []error {
+	var errs []error
+	if u, err := url.Parse(l.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("login url %q is not an http or https URL", l.URL))
+	}
+	for i, step := range l.Steps {
+		if (step.Fill == "") == (step.Click == "") {
+			errs = append(errs, fmt.Errorf("login step %d needs either fill or click", i+1))
+		}
+		if _, ok := os.LookupEnv(step.ValueEnv); step.ValueEnv != "" && !ok {
+			errs = append(errs, fmt.Errorf("login step %d: environment variable %s is not set", i+1, step.ValueEnv))
+		}
+	}
+	return errs
+}
+
+// bindFlags defines the flags of every option with the current values as defaults
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Path, "config", c.Path, "JSON config file, flags and "+EnvPrefix+"* environment variables override its values")
+	fs.Var(&stringsValue{&c.Seeds}, "url", "URL to fetch, comma-separated for several")
+	fs.BoolVar(&c.Static, "static", c.Static, "Enable static scraping")
+	fs.BoolVar(&c.Hybrid, "hybrid", c.Hybrid, "Scrape statically and render the pages that need it with a browser")
+	fs.Var(&stringsValue{&c.Render.Patterns}, "render", "Comma-separated glob patterns of the pages always rendered in hybrid mode")
+	fs.IntVar(&c.Render.MinLinks, "renderMinLinks", c.Render.MinLinks, "Render the pages with fewer links in hybrid mode, 0 disables the check")
+	fs.BoolVar(&c.Render.Markers, "renderMarkers", c.Render.Markers, "Render the pages with an empty application root or a <noscript> warning in hybrid mode")
+	fs.StringVar(&c.Markdown, "markdown", c.Markdown, "Markdown file or directory to check instead of a URL")
+	fs.StringVar(&c.exportFormat, "export", "", "Export file format ("+strings.Join(export.Formats, ", ")+")")
+	fs.StringVar(&c.Filename, "filename", c.Filename, "Export file name")
+	fs.StringVar(&c.exportOutput, "output", "", "Export output path, - for stdout (default <filename> with the extension of the export format)")
+	fs.StringVar(&c.GroupBy, "groupBy", c.GroupBy, "Group the results by page or by dead link target (page, target)")
+	fs.StringVar(&c.Baseline, "baseline", c.Baseline, "JSON export of a previous run, dead links are tagged new, existing or fixed and only new ones fail the run")
+	fs.StringVar(&c.Suppressions, "suppressions", c.Suppressions, "JSON file of acknowledged dead links, reported separately until they expire")
+	fs.StringVar(&c.Input, "input", c.Input, "File of URLs to check without crawling, one per line, - for stdin")
+	fs.Var(&headersValue{&c.Headers}, "header", "Header sent with every request as \"Name: value\", repeatable")
+	fs.StringVar(&c.Cookies, "cookies", c.Cookies, "Netscape or JSON cookie file")
+	fs.Var(&stringsValue{&c.Exclude}, "exclude", "Comma-separated glob patterns of links that are not checked")
+	fs.Var(&stringsValue{&c.Scope}, "scope", "Comma-separated glob patterns of the pages whose links are extracted, every page of the seed's domain if empty")
+	fs.Var(&intsValue{&c.AliveStatuses}, "aliveStatuses", "Comma-separated status codes above 299 that are not dead links")
+	fs.IntVar(&c.MaxDeadLinks, "maxDeadLinks", c.MaxDeadLinks, "Fail the run when more dead links are found, -1 never fails")
+	fs.IntVar(&c.MaxDepth, "maxDepth", c.MaxDepth, "Max depth to scrape")
+	fs.IntVar(&c.MaxConcurrency, "maxConcurrency", c.MaxConcurrency, "Max concurrency")
+	fs.IntVar(&c.Timeout, "timeout", c.Timeout, "Timeout for each request")
+	fs.StringVar(&c.Wait.Until, "waitUntil", c.Wait.Until, "Load state dynamic pages are waited for ("+strings.Join(webscraper.WaitStates, ", ")+")")
+	fs.StringVar(&c.Wait.Selector, "waitFor", c.Wait.Selector, "Selector of an element dynamic pages are waited for before extracting links")
+	fs.StringVar(&c.Wait.Delay, "waitDelay", c.Wait.Delay, "Fixed delay after dynamic pages have loaded, e.g. 500ms")
+	fs.IntVar(&c.Expand.Scrolls, "scrolls", c.Expand.Scrolls, "Scroll dynamic pages to the bottom up to this many times, until they stop growing")
+	fs.StringVar(&c.Expand.Click, "loadMore", c.Expand.Click, "Selector of a \"load more\" button clicked on dynamic pages before extracting links")
+	fs.IntVar(&c.Expand.Clicks, "loadMoreClicks", c.Expand.Clicks, "Maximum clicks of the \"load more\" button")
+	fs.StringVar(&c.Screenshots, "screenshots", c.Screenshots, "Directory screenshots of dynamic pages with dead links are saved to, with the dead links outlined")
+	fs.Var(&stringsValue{&c.Browser.Block}, "block", "Comma-separated resource types dynamic pages don't load ("+strings.Join(webscraper.ResourceTypes, ", ")+")")
+	fs.Var(&stringsValue{&c.Browser.BlockHosts}, "blockHosts", "Comma-separated glob patterns of hosts dynamic pages don't load")
+	fs.BoolVar(&c.Browser.BlockAnalytics, "blockAnalytics", c.Browser.BlockAnalytics, "Don't load common analytics and tag manager hosts in dynamic pages")
+	fs.StringVar(&c.Browser.Device, "device", c.Browser.Device, "Playwright device dynamic pages are rendered on, e.g. \"iPhone 13\"")
+	fs.StringVar(&c.Browser.Viewport, "viewport", c.Browser.Viewport, "Viewport of dynamic pages as WIDTHxHEIGHT, e.g. 390x844")
+	fs.StringVar(&c.Browser.UserAgent, "userAgent", c.Browser.UserAgent, "User agent of the browser in dynamic mode")
+	fs.IntVar(&c.ContextReuse, "contextReuse", c.ContextReuse, "Pages opened in a browser context before it is replaced, 1 disables reuse")
+	fs.StringVar(&c.Addr, "addr", c.Addr, "Address the serve command listens on")
+}
+
+// applyEnv sets the flags that have an environment variable
+func applyEnv(fs *flag.FlagSet) error {
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		name := EnvName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if err := f.Value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for %s: %w", value, name, err))
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable of a flag, e.g. DLH_MAX_DEPTH for maxDepth
+func EnvName(flagName string) string {
+	var b strings.Builder
+	b.WriteString(EnvPrefix)
+	for i, r := range flagName {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// stringsValue is a flag of comma-separated strings
+type stringsValue struct {
+	values *[]string
+}
+
+func (v *stringsValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+	return strings.Join(*v.values, ",")
+}
+
+func (v *stringsValue) Set(value string) error {
+	*v.values = nil
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			*v.values = append(*v.values, s)
+		}
+	}
+	return nil
+}
+
+// headersValue is a repeatable flag of "Name: value" headers
+type headersValue struct {
+	headers *map[string]string
+}
+
+func (v *headersValue) String() string {
+	if v.headers == nil {
+		return ""
+	}
+	var s []string
+	for name, value := range *v.headers {
+		s = append(s, name+": "+value)
+	}
+	return strings.Join(s, ", ")
+}
+
+func (v *headersValue) Set(value string) error {
+	name, headerValue, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return errors.New(`expected "Name: value"`)
+	}
+	if *v.headers == nil {
+		*v.headers = make(map[string]string)
+	}
+	(*v.headers)[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	return nil
+}
+
+// intsValue is a flag of comma-separated integers
+type intsValue struct {
+	values *[]int
+}
+
+func (v *intsValue) String() string {
+	if v.values == nil {
+		return ""
+	}
+	var s []string
+	for _, i := range *v.values {
+		s = append(s, strconv.Itoa(i))
+	}
+	return strings.Join(s, ",")
+}
+
+func (v *intsValue) Set(value string) error {
+	*v.values = nil
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		*v.values = append(*v.values, i)
+	}
+	return nil
+}
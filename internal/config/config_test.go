@@ -0,0 +1,152 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{
+		{"url", "DLH_URL"},
+		{"maxDepth", "DLH_MAX_DEPTH"},
+		{"maxDeadLinks", "DLH_MAX_DEAD_LINKS"},
+		{"blockAnalytics", "DLH_BLOCK_ANALYTICS"},
+		{"renderMinLinks", "DLH_RENDER_MIN_LINKS"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.flag); got != tt.want {
+			t.Errorf("EnvName(%q) = %q, want %q", tt.flag, got, tt.want)
+		}
+	}
+}
+
+// parse parses args with a config file of the given content, if any
+func parse(t *testing.T, content string, args ...string) (*Config, error) {
+	t.Helper()
+	if content != "" {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		args = append([]string{"-config", path}, args...)
+	}
+	fs := flag.NewFlagSet("crawl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return Parse(fs, args)
+}
+
+func TestParsePrecedence(t *testing.T) {
+	const file = `{"seeds": ["https://file.example.com"], "maxDepth": 2, "timeout": 20, "exclude": ["*/file*"], "maxDeadLinks": 3}`
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		args    []string
+		want    func(c *Config) []any
+		wantVal []any
+	}{
+		{
+			name:    "defaults",
+			want:    func(c *Config) []any { return []any{c.MaxDepth, c.Timeout, c.MaxDeadLinks, len(c.Seeds)} },
+			wantVal: []any{5, 10, -1, 0},
+		},
+		{
+			name:    "file over defaults",
+			file:    file,
+			want:    func(c *Config) []any { return []any{c.Seeds, c.MaxDepth, c.Timeout, c.Exclude, c.MaxConcurrency} },
+			wantVal: []any{[]string{"https://file.example.com"}, 2, 20, []string{"*/file*"}, 20},
+		},
+		{
+			name:    "env over file",
+			file:    file,
+			env:     map[string]string{"DLH_MAX_DEPTH": "4", "DLH_EXCLUDE": "*/env*,*/other*"},
+			want:    func(c *Config) []any { return []any{c.MaxDepth, c.Timeout, c.Exclude} },
+			wantVal: []any{4, 20, []string{"*/env*", "*/other*"}},
+		},
+		{
+			name:    "flags over env and file",
+			file:    file,
+			env:     map[string]string{"DLH_MAX_DEPTH": "4", "DLH_TIMEOUT": "30"},
+			args:    []string{"-maxDepth", "1", "-url", "https://flag.example.com,https://other.example.com"},
+			want:    func(c *Config) []any { return []any{c.MaxDepth, c.Timeout, c.Seeds, c.MaxDeadLinks} },
+			wantVal: []any{1, 30, []string{"https://flag.example.com", "https://other.example.com"}, 3},
+		},
+		{
+			name:    "env without config file",
+			env:     map[string]string{"DLH_STATIC": "true"},
+			args:    []string{"-maxDeadLinks", "0"},
+			want:    func(c *Config) []any { return []any{c.Static, c.MaxDeadLinks} },
+			wantVal: []any{true, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			c, err := parse(t, tt.file, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.want(c); !reflect.DeepEqual(got, tt.wantVal) {
+				t.Errorf("got %v, want %v", got, tt.wantVal)
+			}
+		})
+	}
+}
+
+func TestParseExports(t *testing.T) {
+	const one = `{"exports": [{"format": "json", "output": "file.json"}]}`
+	const two = `{"exports": [{"format": "json"}, {"format": "csv"}]}`
+	tests := []struct {
+		name    string
+		file    string
+		args    []string
+		want    []Export
+		wantErr string
+	}{
+		{"file exports", two, nil, []Export{{Format: "json"}, {Format: "csv"}}, ""},
+		{"export flag replaces the file", two, []string{"-export", "html", "-output", "out.html"}, []Export{{Format: "html", Output: "out.html"}}, ""},
+		{"output of the only export", one, []string{"-output", "-"}, []Export{{Format: "json", Output: "-"}}, ""},
+		{"output with several exports", two, []string{"-output", "out"}, nil, "got 2 exports"},
+		{"output without exports", "", []string{"-output", "out"}, nil, "got 0 exports"},
+	}
+	for _, tt := range tests {
+		c, err := parse(t, tt.file, tt.args...)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.Exports, tt.want) {
+			t.Errorf("%s: exports %+v, want %+v", tt.name, c.Exports, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Run("invalid env value", func(t *testing.T) {
+		t.Setenv("DLH_MAX_DEPTH", "deep")
+		if _, err := parse(t, ""); err == nil || !strings.Contains(err.Error(), "DLH_MAX_DEPTH") {
+			t.Errorf("error %v, want the invalid DLH_MAX_DEPTH", err)
+		}
+	})
+	t.Run("unknown config key", func(t *testing.T) {
+		if _, err := parse(t, `{"maxDepht": 3}`); err == nil || !strings.Contains(err.Error(), "maxDepht") {
+			t.Errorf("error %v, want the unknown key", err)
+		}
+	})
+}
@@ -1,8 +1,8 @@
 package export
 
 import (
+	"io"
 	"log"
-	"os"
 	"strconv"
 
 	"github.com/gocarina/gocsv"
@@ -13,21 +13,42 @@ type DeadLinkRow struct {
 	Page      string `csv:"Page,omitempty"`
 	Counts    string `csv:"Counts,omitempty"`
 	DeadLinks string `csv:"Dead Links"`
+	Status    string `csv:"Status"`
+	Line      string `csv:"Line,omitempty"`
+	Column    string `csv:"Column,omitempty"`
+	Selector  string `csv:"Selector,omitempty"`
+	Resource  string `csv:"Resource Type,omitempty"`
+
+	Suppression string `csv:"Suppression,omitempty"`
+	Baseline    string `csv:"Baseline,omitempty"`
 }
 
-type CSVExporter struct{}
+type DeadLinkTargetRow struct {
+	DeadLink string `csv:"Dead Link,omitempty"`
+	Status   string `csv:"Status,omitempty"`
+	Counts   string `csv:"Counts,omitempty"`
+	Pages    string `csv:"Pages"`
 
-func NewCSVExporter() Exporter {
-	return &CSVExporter{}
+	Suppression string `csv:"Suppression,omitempty"`
 }
 
-func (e *CSVExporter) Export(data *map[string]*webscraper.Page, filename string) error {
-	file, err := os.Create(filename + ".csv")
-	if err != nil {
-		log.Printf("Error creating file %s: %v", filename, err)
-		return err
+type CSVExporter struct {
+	view View // How the dead links are grouped
+}
+
+func NewCSVExporter(view View) Exporter {
+	return &CSVExporter{view: view}
+}
+
+func (e *CSVExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	if e.view == ViewTarget {
+		result := e.transformTargets(data)
+		if err := gocsv.Marshal(&result, w); err != nil {
+			log.Printf("Error exporting data to CSV: %v", err)
+			return err
+		}
+		return nil
 	}
-	defer file.Close()
 
 	var result []DeadLinkRow
 
@@ -36,7 +57,7 @@ func (e *CSVExporter) Export(data *map[string]*webscraper.Page, filename string)
 		return err
 	}
 
-	if err := gocsv.MarshalFile(&result, file); err != nil {
+	if err := gocsv.Marshal(&result, w); err != nil {
 		log.Printf("Error exporting data to CSV: %v", err)
 		return err
 	}
@@ -44,14 +65,46 @@ func (e *CSVExporter) Export(data *map[string]*webscraper.Page, filename string)
 }
 
 func (e *CSVExporter) transformData(data *map[string]*webscraper.Page, result *[]DeadLinkRow) error {
-	for url, page := range *data {
-		for i, deadLink := range page.DeadLinks {
+	for _, url := range webscraper.PagesWithAnyDeadLinks(*data) {
+		page := (*data)[url]
+		// Suppressed and fixed dead links follow the reported ones, with the suppression or baseline state filled in
+		deadLinks := append(page.DeadLinks[:len(page.DeadLinks):len(page.DeadLinks)], page.Suppressed...)
+		for i, deadLink := range append(deadLinks, page.Fixed...) {
+			row := DeadLinkRow{DeadLinks: deadLink.URL, Status: deadLink.Reason, Selector: deadLink.Selector, Resource: deadLink.ResourceType, Baseline: string(deadLink.Baseline)}
+			if deadLink.Suppression != nil {
+				row.Suppression = deadLink.Suppression.String()
+			}
+			if deadLink.Line > 0 {
+				row.Line = strconv.Itoa(deadLink.Line)
+			}
+			if deadLink.Column > 0 {
+				row.Column = strconv.Itoa(deadLink.Column)
+			}
 			if i == 0 {
-				*result = append(*result, DeadLinkRow{Page: url, Counts: strconv.Itoa(page.DeadLinkCount), DeadLinks: deadLink})
-			} else {
-				*result = append(*result, DeadLinkRow{DeadLinks: deadLink})
+				row.Page = url
+				row.Counts = strconv.Itoa(page.DeadLinkCount)
 			}
+			*result = append(*result, row)
 		}
 	}
 	return nil
 }
+
+func (e *CSVExporter) transformTargets(data *map[string]*webscraper.Page) []DeadLinkTargetRow {
+	var result []DeadLinkTargetRow
+	// Suppressed targets follow the reported ones, with the suppression filled in
+	for _, target := range append(webscraper.GroupByTarget(*data), webscraper.GroupSuppressedByTarget(*data)...) {
+		for i, page := range target.Pages {
+			if i == 0 {
+				row := DeadLinkTargetRow{DeadLink: target.URL, Status: target.Reason, Counts: strconv.Itoa(target.ReferenceCount), Pages: page}
+				if target.Suppression != nil {
+					row.Suppression = target.Suppression.String()
+				}
+				result = append(result, row)
+			} else {
+				result = append(result, DeadLinkTargetRow{Pages: page})
+			}
+		}
+	}
+	return result
+}
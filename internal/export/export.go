@@ -1,8 +1,65 @@
 package export
 
-import "github.com/yingtu35/dead-link-hunter/internal/webscraper"
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// View selects how the dead links are grouped in an export
+type View string
+
+const (
+	ViewPage   View = "page"   // One entry per page with its dead links
+	ViewTarget View = "target" // One entry per dead link with the pages referencing it
+)
 
 type Exporter interface {
-	// Export exports the data to the specified file
-	Export(data *map[string]*webscraper.Page, filename string) error
+	// Export writes the data to w
+	Export(data *map[string]*webscraper.Page, w io.Writer) error
+}
+
+// StreamExporter is an Exporter that can also write every dead link as soon as it is found
+type StreamExporter interface {
+	Exporter
+
+	// Stream returns a handler for webscraper.ScraperOptions.OnDeadLink that writes to w
+	Stream(w io.Writer) func(page string, deadLink webscraper.DeadLink)
+}
+
+// Formats are the supported export formats
+var Formats = []string{"csv", "json", "ndjson", "html", "sarif", "junit", "markdown"}
+
+// New returns the exporter of a format. The view only applies to CSV and JSON.
+func New(format string, view View) (Exporter, error) {
+	switch strings.ToLower(format) {
+	case "csv":
+		return NewCSVExporter(view), nil
+	case "json":
+		return NewJsonExporter(view), nil
+	case "ndjson":
+		return NewNDJSONExporter(), nil
+	case "html":
+		return NewHTMLExporter(), nil
+	case "sarif":
+		return NewSARIFExporter(), nil
+	case "junit":
+		return NewJUnitExporter(), nil
+	case "markdown":
+		return NewMarkdownExporter(), nil
+	}
+	return nil, fmt.Errorf("invalid export format %q", format)
+}
+
+// FileExtension returns the extension of an export file of the format
+func FileExtension(format string) string {
+	switch format = strings.ToLower(format); format {
+	case "junit":
+		return "xml"
+	case "markdown":
+		return "md"
+	}
+	return format
 }
@@ -0,0 +1,308 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+type htmlCount struct {
+	Name  string
+	Count int
+}
+
+type htmlRow struct {
+	Page     string
+	URL      string
+	Status   string
+	Type     string
+	Location string
+	Selector string
+	Resource string // The resource type of a failed subresource request
+	Baseline string // How the dead link compares to the baseline, empty without one
+}
+
+type htmlSuppressedRow struct {
+	Page    string
+	URL     string
+	Status  string
+	Owner   string
+	Reason  string
+	Expires string
+}
+
+type htmlConsoleRow struct {
+	Page     string
+	Type     string
+	Text     string
+	Location string
+}
+
+type htmlPage struct {
+	URL        string
+	Count      int
+	DeadLinks  []webscraper.DeadLink
+	Screenshot string
+}
+
+type htmlReport struct {
+	GeneratedAt   string
+	PageCount     int
+	DeadLinkCount int
+	TargetCount   int
+	Types         []htmlCount
+	Statuses      []htmlCount
+	Rows          []htmlRow
+	Pages         []htmlPage
+	Suppressed    []htmlSuppressedRow
+	Console       []htmlConsoleRow
+
+	Baseline bool      // Set when the dead links were compared to a baseline
+	NewCount int       // The number of dead links that are not in the baseline
+	Fixed    []htmlRow // The dead links of the baseline that are no longer found
+}
+
+type HTMLExporter struct{}
+
+func NewHTMLExporter() Exporter {
+	return &HTMLExporter{}
+}
+
+func (e *HTMLExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	if err := htmlTemplate.Execute(w, e.transformData(data)); err != nil {
+		log.Printf("Error exporting data to HTML: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (e *HTMLExporter) transformData(data *map[string]*webscraper.Page) htmlReport {
+	report := htmlReport{
+		GeneratedAt: time.Now().Format(time.RFC1123),
+		TargetCount: len(webscraper.GroupByTarget(*data)),
+	}
+
+	types := make(map[string]int)
+	statuses := make(map[string]int)
+	for _, url := range webscraper.PagesWithDeadLinks(*data) {
+		page := (*data)[url]
+		report.PageCount++
+		report.DeadLinkCount += page.DeadLinkCount
+		report.Pages = append(report.Pages, htmlPage{URL: url, Count: page.DeadLinkCount, DeadLinks: page.DeadLinks, Screenshot: page.Screenshot})
+		for _, deadLink := range page.DeadLinks {
+			types[string(deadLink.Category())]++
+			statuses[deadLink.Reason]++
+			report.Rows = append(report.Rows, htmlRow{
+				Page:     url,
+				URL:      deadLink.URL,
+				Status:   deadLink.Reason,
+				Type:     string(deadLink.Category()),
+				Location: deadLink.Location(),
+				Selector: deadLink.Selector,
+				Resource: deadLink.ResourceType,
+				Baseline: string(deadLink.Baseline),
+			})
+			if deadLink.Baseline != "" {
+				report.Baseline = true
+			}
+			if deadLink.Baseline == webscraper.BaselineNew {
+				report.NewCount++
+			}
+		}
+	}
+	for _, url := range webscraper.PagesWithAnyDeadLinks(*data) {
+		for _, deadLink := range (*data)[url].Fixed {
+			report.Baseline = true
+			report.Fixed = append(report.Fixed, htmlRow{
+				Page:     url,
+				URL:      deadLink.URL,
+				Status:   deadLink.Reason,
+				Type:     string(deadLink.Category()),
+				Location: deadLink.Location(),
+				Selector: deadLink.Selector,
+				Resource: deadLink.ResourceType,
+				Baseline: string(deadLink.Baseline),
+			})
+		}
+	}
+	for _, url := range webscraper.PagesWithSuppressedLinks(*data) {
+		for _, deadLink := range (*data)[url].Suppressed {
+			report.Suppressed = append(report.Suppressed, htmlSuppressedRow{
+				Page:    url,
+				URL:     deadLink.URL,
+				Status:  deadLink.Reason,
+				Owner:   deadLink.Suppression.Owner,
+				Reason:  deadLink.Suppression.Reason,
+				Expires: deadLink.Suppression.Expires,
+			})
+		}
+	}
+	for _, url := range webscraper.PagesWithConsoleMessages(*data) {
+		for _, message := range (*data)[url].ConsoleMessages {
+			report.Console = append(report.Console, htmlConsoleRow{Page: url, Type: message.Type, Text: message.Text, Location: message.Location})
+		}
+	}
+	report.Types = sortedCounts(types)
+	report.Statuses = sortedCounts(statuses)
+	return report
+}
+
+// sortedCounts returns the counts sorted by count, largest first
+func sortedCounts(counts map[string]int) []htmlCount {
+	result := make([]htmlCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, htmlCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Dead Link Report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: 0.25rem; }
+.generated { color: #59636e; margin-top: 0; }
+.stats { display: flex; flex-wrap: wrap; gap: 1rem; margin: 1.5rem 0; }
+.stat { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.75rem 1.25rem; min-width: 8rem; }
+.stat .value { font-size: 1.75rem; font-weight: 600; }
+.stat .label { color: #59636e; }
+.breakdown { display: flex; flex-wrap: wrap; gap: 2rem; }
+.breakdown ul { padding-left: 1.25rem; }
+.filters { display: flex; flex-wrap: wrap; gap: 0.5rem; margin: 1rem 0; }
+.filters input, .filters select { padding: 0.35rem 0.5rem; border: 1px solid #d1d9e0; border-radius: 6px; }
+.filters input { flex: 1; min-width: 16rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #d1d9e0; vertical-align: top; word-break: break-all; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; white-space: nowrap; }
+th[data-order="asc"]::after { content: " \25B2"; }
+th[data-order="desc"]::after { content: " \25BC"; }
+code { font-size: 0.85em; }
+details { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.5rem 0.75rem; margin: 0.5rem 0; }
+summary { cursor: pointer; font-weight: 600; word-break: break-all; }
+.count { color: #cf222e; }
+.screenshot { max-width: 100%; max-height: 40rem; border: 1px solid #d1d9e0; }
+.empty { color: #1a7f37; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>Dead Link Report</h1>
+<p class="generated">Generated {{.GeneratedAt}}</p>
+{{if not .Rows}}
+<p class="empty">No dead links found</p>
+{{else}}
+<div class="stats">
+  <div class="stat"><div class="value">{{.DeadLinkCount}}</div><div class="label">Dead links</div></div>
+  <div class="stat"><div class="value">{{.TargetCount}}</div><div class="label">Unique targets</div></div>
+  <div class="stat"><div class="value">{{.PageCount}}</div><div class="label">Pages affected</div></div>
+  {{if .Baseline}}<div class="stat"><div class="value">{{.NewCount}}</div><div class="label">New since the baseline</div></div>{{end}}
+  {{if .Suppressed}}<div class="stat"><div class="value">{{len .Suppressed}}</div><div class="label">Suppressed</div></div>{{end}}
+  {{if .Fixed}}<div class="stat"><div class="value">{{len .Fixed}}</div><div class="label">Fixed</div></div>{{end}}
+</div>
+<div class="breakdown">
+  <div><h3>By type</h3><ul>{{range .Types}}<li>{{.Name}}: {{.Count}}</li>{{end}}</ul></div>
+  <div><h3>By status</h3><ul>{{range .Statuses}}<li>{{.Name}}: {{.Count}}</li>{{end}}</ul></div>
+</div>
+
+<h2>Dead links</h2>
+<div class="filters">
+  <input id="search" type="search" placeholder="Filter by page or target">
+  <select id="type"><option value="">All types</option>{{range .Types}}<option>{{.Name}}</option>{{end}}</select>
+  <select id="status"><option value="">All statuses</option>{{range .Statuses}}<option>{{.Name}}</option>{{end}}</select>
+  {{if .Baseline}}<select id="baseline"><option value="">New and existing</option><option>new</option><option>existing</option></select>{{end}}
+</div>
+<table id="dead-links">
+<thead><tr><th>Status</th><th>Page</th><th>Target</th><th>Type</th><th>Location</th>{{if .Baseline}}<th>Baseline</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr data-type="{{.Type}}" data-status="{{.Status}}" data-baseline="{{.Baseline}}"><td>{{.Status}}</td><td><a href="{{.Page}}">{{.Page}}</a></td><td>{{.URL}}{{if .Resource}} <small>({{.Resource}})</small>{{end}}</td><td>{{.Type}}</td><td>{{.Location}}{{if .Selector}} <code title="Playwright selector, not CSS when chained with &gt;&gt;">{{.Selector}}</code>{{end}}</td>{{if $.Baseline}}<td>{{.Baseline}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+
+<h2>Pages</h2>
+{{range .Pages}}<details>
+<summary>{{.URL}} <span class="count">({{.Count}})</span></summary>
+<ul>{{range .DeadLinks}}<li>{{.URL}} &mdash; {{.Reason}}{{with .Location}} at {{.}}{{end}}</li>{{end}}</ul>
+{{with .Screenshot}}<a href="{{.}}"><img class="screenshot" src="{{.}}" alt="Screenshot with the dead links outlined" loading="lazy"></a>{{end}}
+</details>
+{{end}}
+<script>
+(function () {
+  if (!document.getElementById("dead-links")) return;
+  var table = document.getElementById("dead-links");
+  var rows = Array.prototype.slice.call(table.tBodies[0].rows);
+  var search = document.getElementById("search");
+  var type = document.getElementById("type");
+  var status = document.getElementById("status");
+  var baseline = document.getElementById("baseline");
+
+  function filter() {
+    var text = search.value.toLowerCase();
+    rows.forEach(function (row) {
+      var visible = (!text || row.textContent.toLowerCase().indexOf(text) >= 0) &&
+        (!type.value || row.dataset.type === type.value) &&
+        (!status.value || row.dataset.status === status.value) &&
+        (!baseline || !baseline.value || row.dataset.baseline === baseline.value);
+      row.style.display = visible ? "" : "none";
+    });
+  }
+  [search, type, status, baseline].forEach(function (el) { if (el) el.addEventListener("input", filter); });
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th, column) {
+    th.addEventListener("click", function () {
+      var order = th.dataset.order === "asc" ? "desc" : "asc";
+      Array.prototype.forEach.call(table.tHead.rows[0].cells, function (other) { delete other.dataset.order; });
+      th.dataset.order = order;
+      rows.sort(function (a, b) {
+        var x = a.cells[column].textContent, y = b.cells[column].textContent;
+        var result = x.localeCompare(y, undefined, { numeric: true });
+        return order === "asc" ? result : -result;
+      });
+      rows.forEach(function (row) { table.tBodies[0].appendChild(row); });
+    });
+  });
+})();
+</script>
+{{end}}
+{{if .Suppressed}}
+<h2>Suppressed dead links</h2>
+<table id="suppressed">
+<thead><tr><th>Status</th><th>Page</th><th>Target</th><th>Owner</th><th>Reason</th><th>Expires</th></tr></thead>
+<tbody>
+{{range .Suppressed}}<tr><td>{{.Status}}</td><td><a href="{{.Page}}">{{.Page}}</a></td><td>{{.URL}}</td><td>{{.Owner}}</td><td>{{.Reason}}</td><td>{{.Expires}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+{{if .Fixed}}
+<h2>Fixed since the baseline</h2>
+<table id="fixed">
+<thead><tr><th>Status</th><th>Page</th><th>Target</th><th>Type</th><th>Location</th></tr></thead>
+<tbody>
+{{range .Fixed}}<tr><td>{{.Status}}</td><td><a href="{{.Page}}">{{.Page}}</a></td><td>{{.URL}}{{if .Resource}} <small>({{.Resource}})</small>{{end}}</td><td>{{.Type}}</td><td>{{.Location}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+{{if .Console}}
+<h2>Console messages</h2>
+<table id="console">
+<thead><tr><th>Type</th><th>Page</th><th>Message</th><th>Location</th></tr></thead>
+<tbody>
+{{range .Console}}<tr><td>{{.Type}}</td><td><a href="{{.Page}}">{{.Page}}</a></td><td><code>{{.Text}}</code></td><td>{{.Location}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+</body>
+</html>
+`))
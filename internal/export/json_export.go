@@ -2,41 +2,121 @@ package export
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
-	"os"
 
 	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
 )
 
 type Record struct {
-	Page      string   `json:"Page"`
-	Counts    int      `json:"Counts"`
-	DeadLinks []string `json:"Dead Links"`
+	Page       string           `json:"Page"`
+	Counts     int              `json:"Counts"`
+	DeadLinks  []DeadLinkRecord `json:"Dead Links"`
+	Suppressed []DeadLinkRecord `json:"Suppressed,omitempty"`
+	Fixed      []DeadLinkRecord `json:"Fixed,omitempty"`
+
+	ConsoleMessages []ConsoleMessageRecord `json:"Console Messages,omitempty"`
+	Screenshot      string                 `json:"Screenshot,omitempty"`
 }
 
-type JsonExporter struct{}
+type DeadLinkRecord struct {
+	URL        string `json:"URL"`
+	StatusCode int    `json:"Status Code,omitempty"`
+	Reason     string `json:"Reason"`
+	Line       int    `json:"Line,omitempty"`
+	Column     int    `json:"Column,omitempty"`
+	Selector   string `json:"Selector,omitempty"`
+
+	ResourceType string `json:"Resource Type,omitempty"`
+
+	Suppression *SuppressionRecord `json:"Suppression,omitempty"`
 
-func NewJsonExporter() Exporter {
-	return &JsonExporter{}
+	Baseline string `json:"Baseline,omitempty"`
 }
 
-func (e *JsonExporter) Export(data *map[string]*webscraper.Page, filename string) error {
-	file, err := os.Create(filename + ".json")
-	if err != nil {
-		log.Printf("Error creating file %s: %v", filename, err)
-		return err
+type SuppressionRecord struct {
+	Owner   string `json:"Owner"`
+	Reason  string `json:"Reason"`
+	Expires string `json:"Expires,omitempty"`
+}
+
+func newDeadLinkRecord(deadLink webscraper.DeadLink) DeadLinkRecord {
+	record := DeadLinkRecord{
+		URL:        deadLink.URL,
+		StatusCode: deadLink.StatusCode,
+		Reason:     deadLink.Reason,
+		Line:       deadLink.Line,
+		Column:     deadLink.Column,
+		Selector:   deadLink.Selector,
+
+		ResourceType: deadLink.ResourceType,
+
+		Baseline: string(deadLink.Baseline),
+	}
+	if suppression := deadLink.Suppression; suppression != nil {
+		record.Suppression = &SuppressionRecord{Owner: suppression.Owner, Reason: suppression.Reason, Expires: suppression.Expires}
 	}
+	return record
+}
 
-	var result []Record
-	e.transformData(data, &result)
+func (r DeadLinkRecord) deadLink() webscraper.DeadLink {
+	deadLink := webscraper.DeadLink{
+		URL:        r.URL,
+		StatusCode: r.StatusCode,
+		Reason:     r.Reason,
+		Line:       r.Line,
+		Column:     r.Column,
+		Selector:   r.Selector,
 
-	resultJson, err := json.MarshalIndent(result, "", "    ")
-	if err != nil {
-		log.Printf("Error marshalling data: %v", err)
-		return err
+		ResourceType: r.ResourceType,
+
+		Baseline: webscraper.BaselineState(r.Baseline),
+	}
+	if suppression := r.Suppression; suppression != nil {
+		deadLink.Suppression = &webscraper.Suppression{Owner: suppression.Owner, Reason: suppression.Reason, Expires: suppression.Expires}
+	}
+	return deadLink
+}
+
+type ConsoleMessageRecord struct {
+	Type     string `json:"Type"`
+	Text     string `json:"Text"`
+	Location string `json:"Location,omitempty"`
+}
+
+type TargetRecord struct {
+	DeadLink   string   `json:"Dead Link"`
+	StatusCode int      `json:"Status Code,omitempty"`
+	Reason     string   `json:"Reason"`
+	Counts     int      `json:"Counts"`
+	Pages      []string `json:"Pages"`
+
+	Suppression *SuppressionRecord `json:"Suppression,omitempty"`
+}
+
+type JsonExporter struct {
+	view View // How the dead links are grouped
+}
+
+func NewJsonExporter(view View) Exporter {
+	return &JsonExporter{view: view}
+}
+
+func (e *JsonExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	var result interface{}
+	if e.view == ViewTarget {
+		result = e.transformTargets(data)
+	} else {
+		var records []Record
+		e.transformData(data, &records)
+		result = records
 	}
 
-	if _, err := file.Write(resultJson); err != nil {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(result); err != nil {
 		log.Printf("Error exporting data to JSON: %v", err)
 		return err
 	}
@@ -44,11 +124,77 @@ func (e *JsonExporter) Export(data *map[string]*webscraper.Page, filename string
 }
 
 func (e *JsonExporter) transformData(data *map[string]*webscraper.Page, result *[]Record) {
-	for url, page := range *data {
-		*result = append(*result, Record{
+	for _, url := range webscraper.PagesWithFindings(*data) {
+		page := (*data)[url]
+		record := Record{
 			Page:      url,
 			Counts:    page.DeadLinkCount,
-			DeadLinks: page.DeadLinks,
-		})
+			DeadLinks: []DeadLinkRecord{},
+
+			Screenshot: page.Screenshot,
+		}
+		for _, deadLink := range page.DeadLinks {
+			record.DeadLinks = append(record.DeadLinks, newDeadLinkRecord(deadLink))
+		}
+		for _, deadLink := range page.Suppressed {
+			record.Suppressed = append(record.Suppressed, newDeadLinkRecord(deadLink))
+		}
+		for _, deadLink := range page.Fixed {
+			record.Fixed = append(record.Fixed, newDeadLinkRecord(deadLink))
+		}
+		for _, message := range page.ConsoleMessages {
+			record.ConsoleMessages = append(record.ConsoleMessages, ConsoleMessageRecord(message))
+		}
+		*result = append(*result, record)
+	}
+}
+
+// transformTargets lists the dead link targets, followed by the suppressed
+// ones with their suppression filled in
+func (e *JsonExporter) transformTargets(data *map[string]*webscraper.Page) []TargetRecord {
+	var result []TargetRecord
+	for _, target := range append(webscraper.GroupByTarget(*data), webscraper.GroupSuppressedByTarget(*data)...) {
+		record := TargetRecord{
+			DeadLink:   target.URL,
+			StatusCode: target.StatusCode,
+			Reason:     target.Reason,
+			Counts:     target.ReferenceCount,
+			Pages:      target.Pages,
+		}
+		if suppression := target.Suppression; suppression != nil {
+			record.Suppression = &SuppressionRecord{Owner: suppression.Owner, Reason: suppression.Reason, Expires: suppression.Expires}
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// ReadJSON reads the results of a JSON export made with the page view
+func ReadJSON(r io.Reader) (map[string]*webscraper.Page, error) {
+	var records []Record
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	pages := make(map[string]*webscraper.Page)
+	for _, record := range records {
+		if record.Page == "" {
+			return nil, errors.New("record without a page, only exports grouped by page can be read")
+		}
+		page := &webscraper.Page{DeadLinkCount: record.Counts, DeadLinks: []webscraper.DeadLink{}, CheckedLinks: []string{}, Screenshot: record.Screenshot}
+		for _, deadLink := range record.DeadLinks {
+			page.DeadLinks = append(page.DeadLinks, deadLink.deadLink())
+		}
+		for _, deadLink := range record.Suppressed {
+			page.Suppressed = append(page.Suppressed, deadLink.deadLink())
+		}
+		for _, deadLink := range record.Fixed {
+			page.Fixed = append(page.Fixed, deadLink.deadLink())
+		}
+		for _, message := range record.ConsoleMessages {
+			page.ConsoleMessages = append(page.ConsoleMessages, webscraper.ConsoleMessage(message))
+		}
+		pages[record.Page] = page
 	}
+	return pages, nil
 }
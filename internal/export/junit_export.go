@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"sort"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"` // The console messages of the page
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type JUnitExporter struct{}
+
+func NewJUnitExporter() Exporter {
+	return &JUnitExporter{}
+}
+
+func (e *JUnitExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		log.Printf("Error exporting data to JUnit: %v", err)
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(e.transformData(data)); err != nil {
+		log.Printf("Error exporting data to JUnit: %v", err)
+		return err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		log.Printf("Error exporting data to JUnit: %v", err)
+		return err
+	}
+	return nil
+}
+
+// transformData turns every crawled page into a test suite with one test case
+// per checked link, failing if the link is dead and skipped if it is suppressed
+func (e *JUnitExporter) transformData(data *map[string]*webscraper.Page) junitTestSuites {
+	result := junitTestSuites{Name: toolName}
+
+	urls := make([]string, 0, len(*data))
+	for url := range *data {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		page := (*data)[url]
+		suite := junitTestSuite{Name: url}
+
+		// A link can appear more than once on a page, match each occurrence to one dead link
+		deadLinks := make(map[string][]webscraper.DeadLink)
+		allDeadLinks := append(page.DeadLinks[:len(page.DeadLinks):len(page.DeadLinks)], page.Suppressed...)
+		for _, deadLink := range allDeadLinks {
+			deadLinks[deadLink.URL] = append(deadLinks[deadLink.URL], deadLink)
+		}
+		// The checked links are in document order, the dead links in the order they were found
+		for _, dead := range deadLinks {
+			sort.SliceStable(dead, func(i, j int) bool {
+				if dead[i].Line != dead[j].Line {
+					return dead[i].Line < dead[j].Line
+				}
+				return dead[i].Column < dead[j].Column
+			})
+		}
+
+		for _, link := range page.CheckedLinks {
+			testCase := junitTestCase{Name: link, ClassName: url}
+			if dead := deadLinks[link]; len(dead) > 0 {
+				setJUnitResult(&testCase, dead[0])
+				deadLinks[link] = dead[1:]
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		// Dead links that weren't recorded as checked still fail
+		for _, deadLink := range allDeadLinks {
+			if dead := deadLinks[deadLink.URL]; len(dead) > 0 {
+				testCase := junitTestCase{Name: deadLink.URL, ClassName: url}
+				setJUnitResult(&testCase, dead[0])
+				suite.TestCases = append(suite.TestCases, testCase)
+				deadLinks[deadLink.URL] = dead[1:]
+			}
+		}
+
+		for _, testCase := range suite.TestCases {
+			suite.Tests++
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		for _, message := range page.ConsoleMessages {
+			suite.SystemErr += message.String() + "\n"
+		}
+		result.Tests += suite.Tests
+		result.Failures += suite.Failures
+		result.Skipped += suite.Skipped
+		result.Suites = append(result.Suites, suite)
+	}
+	return result
+}
+
+// setJUnitResult marks the test case of a dead link as failed, or skipped if the dead link is suppressed
+func setJUnitResult(testCase *junitTestCase, deadLink webscraper.DeadLink) {
+	if deadLink.Suppression != nil {
+		testCase.Skipped = &junitSkipped{Message: "Suppressed " + deadLink.Reason + " by " + deadLink.Suppression.String()}
+		return
+	}
+	testCase.Failure = newJUnitFailure(deadLink)
+}
+
+func newJUnitFailure(deadLink webscraper.DeadLink) *junitFailure {
+	text := deadLink.URL + ": " + deadLink.Reason
+	if location := deadLink.Location(); location != "" {
+		text += " at " + location
+	}
+	if deadLink.Selector != "" {
+		text += " (" + deadLink.Selector + ")"
+	}
+	if deadLink.ResourceType != "" {
+		text += " (" + deadLink.ResourceType + " loaded by the page)"
+	}
+	return &junitFailure{
+		Message: deadLink.Reason,
+		Type:    string(deadLink.Category()),
+		Text:    text,
+	}
+}
@@ -0,0 +1,207 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+const (
+	// MarkdownMaxSize keeps the summary below the 65536 character limit of
+	// pull request comments, with room left for text added around it
+	MarkdownMaxSize = 60000
+	// markdownTopPages is the number of pages listed as top offenders
+	markdownTopPages = 10
+)
+
+type MarkdownExporter struct {
+	maxSize int // The maximum size of the summary in bytes
+}
+
+func NewMarkdownExporter() Exporter {
+	return &MarkdownExporter{maxSize: MarkdownMaxSize}
+}
+
+func (e *MarkdownExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	if _, err := io.WriteString(w, e.transformData(data)); err != nil {
+		log.Printf("Error exporting data to Markdown: %v", err)
+		return err
+	}
+	return nil
+}
+
+// transformData renders the summary: the totals, the pages with the most dead
+// links, a collapsible table of every dead link and the suppressed dead links
+// and console messages. Rows that don't fit in maxSize are left out and
+// counted in a note instead, the dead links table leaves room for the notes of
+// the sections after it.
+func (e *MarkdownExporter) transformData(data *map[string]*webscraper.Page) string {
+	var b strings.Builder
+	b.WriteString("## Dead Link Report\n\n")
+
+	suppressed := webscraper.SuppressedCount(*data)
+	messages := webscraper.ConsoleMessageCount(*data)
+	urls := webscraper.PagesWithDeadLinks(*data)
+	if len(urls) == 0 {
+		b.WriteString("No dead links found :tada:\n")
+		writeBaseline(&b, data)
+		e.writeSuppressed(&b, data, len(consoleNote(messages)))
+		e.writeConsoleMessages(&b, data)
+		return b.String()
+	}
+
+	total := 0
+	categories := make(map[string]int)
+	for _, url := range urls {
+		total += (*data)[url].DeadLinkCount
+		for _, deadLink := range (*data)[url].DeadLinks {
+			categories[string(deadLink.Category())]++
+		}
+	}
+	fmt.Fprintf(&b, "Found **%d** dead links (%d unique) on **%d** pages.\n", total, len(webscraper.GroupByTarget(*data)), len(urls))
+	writeBaseline(&b, data)
+	b.WriteString("\n")
+
+	b.WriteString("| Type | Count |\n|------|------:|\n")
+	for _, count := range sortedCounts(categories) {
+		fmt.Fprintf(&b, "| %s | %d |\n", count.Name, count.Count)
+	}
+
+	topPages := append([]string(nil), urls...)
+	sort.SliceStable(topPages, func(i, j int) bool {
+		return (*data)[topPages[i]].DeadLinkCount > (*data)[topPages[j]].DeadLinkCount
+	})
+	if len(topPages) > markdownTopPages {
+		topPages = topPages[:markdownTopPages]
+	}
+	b.WriteString("\n### Top pages\n\n| Page | Dead Links |\n|------|-----------:|\n")
+	for _, url := range topPages {
+		fmt.Fprintf(&b, "| %s | %d |\n", markdownCell(url), (*data)[url].DeadLinkCount)
+	}
+
+	b.WriteString("\n<details>\n<summary>All dead links</summary>\n\n| Page | Dead Link | Status | Location |\n|------|-----------|--------|----------|\n")
+	const footer = "\n</details>\n"
+	// Room for the truncation note and the sections after the table
+	budget := e.maxSize - len(footer) - 100 - len(suppressedNote(suppressed)) - len(consoleNote(messages))
+	shown := 0
+rows:
+	for _, url := range urls {
+		for _, deadLink := range (*data)[url].DeadLinks {
+			row := fmt.Sprintf("| %s | %s | %s | %s |\n", markdownCell(url), markdownCell(deadLink.URL), markdownCell(deadLink.Reason), deadLink.Location())
+			if b.Len()+len(row) > budget {
+				break rows
+			}
+			b.WriteString(row)
+			shown++
+		}
+	}
+	if shown < total {
+		fmt.Fprintf(&b, "\n_%d more dead links not shown, see the full export for details._\n", total-shown)
+	}
+	b.WriteString(footer)
+	e.writeSuppressed(&b, data, len(consoleNote(messages)))
+	e.writeConsoleMessages(&b, data)
+	return b.String()
+}
+
+// writeBaseline adds the number of new, still broken and fixed dead links if
+// they were compared to a baseline
+func writeBaseline(b *strings.Builder, data *map[string]*webscraper.Page) {
+	counts := make(map[webscraper.BaselineState]int)
+	for _, page := range *data {
+		for _, deadLink := range page.DeadLinks {
+			counts[deadLink.Baseline]++
+		}
+		counts[webscraper.BaselineFixed] += len(page.Fixed)
+	}
+	if counts[webscraper.BaselineNew]+counts[webscraper.BaselineExisting]+counts[webscraper.BaselineFixed] == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\nAgainst the baseline: **%d** new, %d still broken, %d fixed.\n", counts[webscraper.BaselineNew], counts[webscraper.BaselineExisting], counts[webscraper.BaselineFixed])
+}
+
+// writeSuppressed adds a collapsible list of the suppressed dead links, if any
+// and if it fits in maxSize with reserve bytes left, or a note otherwise
+func (e *MarkdownExporter) writeSuppressed(b *strings.Builder, data *map[string]*webscraper.Page, reserve int) {
+	count := webscraper.SuppressedCount(*data)
+	if count == 0 {
+		return
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "\n<details>\n<summary>%d suppressed dead links</summary>\n\n| Page | Dead Link | Status | Owner | Reason | Expires |\n|------|-----------|--------|-------|--------|---------|\n", count)
+	for _, url := range webscraper.PagesWithSuppressedLinks(*data) {
+		for _, deadLink := range (*data)[url].Suppressed {
+			suppression := deadLink.Suppression
+			fmt.Fprintf(&s, "| %s | %s | %s | %s | %s | %s |\n", markdownCell(url), markdownCell(deadLink.URL), markdownCell(deadLink.Reason), markdownCell(suppression.Owner), markdownCell(suppression.Reason), suppression.Expires)
+		}
+	}
+	s.WriteString("\n</details>\n")
+
+	if b.Len()+s.Len()+reserve > e.maxSize {
+		b.WriteString(suppressedNote(count))
+		return
+	}
+	b.WriteString(s.String())
+}
+
+// suppressedNote is written instead of the suppressed dead links that don't fit
+func suppressedNote(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n_%d suppressed dead links not shown, see the full export for details._\n", count)
+}
+
+// writeConsoleMessages adds a collapsible list of the console messages, if any,
+// leaving out the rows that don't fit in maxSize
+func (e *MarkdownExporter) writeConsoleMessages(b *strings.Builder, data *map[string]*webscraper.Page) {
+	count := webscraper.ConsoleMessageCount(*data)
+	if count == 0 {
+		return
+	}
+
+	header := fmt.Sprintf("\n<details>\n<summary>%d console messages</summary>\n\n| Page | Type | Message | Location |\n|------|------|---------|----------|\n", count)
+	const footer = "\n</details>\n"
+	// Room for the truncation note
+	budget := e.maxSize - len(footer) - 100
+	if b.Len()+len(header) > budget {
+		b.WriteString(consoleNote(count))
+		return
+	}
+	b.WriteString(header)
+	shown := 0
+rows:
+	for _, url := range webscraper.PagesWithConsoleMessages(*data) {
+		for _, message := range (*data)[url].ConsoleMessages {
+			row := fmt.Sprintf("| %s | %s | %s | %s |\n", markdownCell(url), message.Type, markdownCell(message.Text), markdownCell(message.Location))
+			if b.Len()+len(row) > budget {
+				break rows
+			}
+			b.WriteString(row)
+			shown++
+		}
+	}
+	if shown < count {
+		fmt.Fprintf(b, "\n_%d more console messages not shown, see the full export for details._\n", count-shown)
+	}
+	b.WriteString(footer)
+}
+
+// consoleNote is written instead of the console messages when none of them fit
+func consoleNote(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n_%d console messages not shown, see the full export for details._\n", count)
+}
+
+// markdownCell escapes a value for use in a Markdown table cell
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.Join(strings.Fields(value), " ")
+}
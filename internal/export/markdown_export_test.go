@@ -0,0 +1,84 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// markdownPages returns a page with the given number of dead links,
+// suppressed dead links and console messages
+func markdownPages(deadLinks, suppressed, messages int) *map[string]*webscraper.Page {
+	page := &webscraper.Page{DeadLinkCount: deadLinks}
+	for i := 0; i < deadLinks; i++ {
+		page.DeadLinks = append(page.DeadLinks, webscraper.DeadLink{URL: fmt.Sprintf("https://example.com/dead/%d", i), Reason: "404 Not Found"})
+	}
+	for i := 0; i < suppressed; i++ {
+		page.Suppressed = append(page.Suppressed, webscraper.DeadLink{
+			URL:         fmt.Sprintf("https://example.com/suppressed/%d", i),
+			Reason:      "404 Not Found",
+			Suppression: &webscraper.Suppression{Owner: "docs", Reason: "known"},
+		})
+	}
+	for i := 0; i < messages; i++ {
+		page.ConsoleMessages = append(page.ConsoleMessages, webscraper.ConsoleMessage{Type: "error", Text: fmt.Sprintf("message %d", i)})
+	}
+	return &map[string]*webscraper.Page{"https://example.com/": page}
+}
+
+func TestMarkdownMaxSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxSize int
+		data    *map[string]*webscraper.Page
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "everything fits",
+			maxSize: MarkdownMaxSize,
+			data:    markdownPages(3, 2, 2),
+			want:    []string{"/dead/2 |", "2 suppressed dead links</summary>", "2 console messages</summary>", "| message 1 |"},
+			notWant: []string{"not shown"},
+		},
+		{
+			name:    "sections after a long table",
+			maxSize: 2000,
+			data:    markdownPages(200, 50, 50),
+			want:    []string{"more dead links not shown", "_50 suppressed dead links not shown", "_50 console messages not shown"},
+		},
+		{
+			name:    "console rows after the suppressed section",
+			maxSize: 2500,
+			data:    markdownPages(0, 5, 200),
+			want:    []string{"5 suppressed dead links</summary>", "more console messages not shown"},
+		},
+		{
+			name:    "no dead links with a long suppressed section",
+			maxSize: 1000,
+			data:    markdownPages(0, 100, 100),
+			want:    []string{"No dead links found", "_100 suppressed dead links not shown", "more console messages not shown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &MarkdownExporter{maxSize: tt.maxSize}
+			got := e.transformData(tt.data)
+			if len(got) > tt.maxSize {
+				t.Errorf("summary is %d bytes, want at most %d", len(got), tt.maxSize)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("summary does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("summary contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// LineRecord is a single dead link written as one line of NDJSON
+type LineRecord struct {
+	Page string `json:"Page"`
+	DeadLinkRecord
+}
+
+type NDJSONExporter struct{}
+
+func NewNDJSONExporter() Exporter {
+	return &NDJSONExporter{}
+}
+
+func (e *NDJSONExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, url := range webscraper.PagesWithAnyDeadLinks(*data) {
+		page := (*data)[url]
+		// Suppressed and fixed dead links carry their suppression or baseline state, telling them apart
+		deadLinks := append(page.DeadLinks[:len(page.DeadLinks):len(page.DeadLinks)], page.Suppressed...)
+		for _, deadLink := range append(deadLinks, page.Fixed...) {
+			if err := encoder.Encode(LineRecord{Page: url, DeadLinkRecord: newDeadLinkRecord(deadLink)}); err != nil {
+				log.Printf("Error exporting data to NDJSON: %v", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *NDJSONExporter) Stream(w io.Writer) func(page string, deadLink webscraper.DeadLink) {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return func(page string, deadLink webscraper.DeadLink) {
+		if err := encoder.Encode(LineRecord{Page: page, DeadLinkRecord: newDeadLinkRecord(deadLink)}); err != nil {
+			log.Printf("Error exporting data to NDJSON: %v", err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// RenderDiffRecord compares the links of a page with and without JavaScript
+type RenderDiffRecord struct {
+	Page           string   `json:"Page"`
+	NeedsRendering bool     `json:"Needs Rendering"`
+	RenderedOnly   []string `json:"Rendered Only"`
+	StaticOnly     []string `json:"Static Only"`
+	Common         int      `json:"Common"`
+}
+
+// ExportRenderDiffs writes the comparisons of the pages as JSON
+func ExportRenderDiffs(diffs []webscraper.RenderDiff, w io.Writer) error {
+	records := []RenderDiffRecord{}
+	for _, diff := range diffs {
+		records = append(records, RenderDiffRecord{
+			Page:           diff.Page,
+			NeedsRendering: diff.NeedsRendering(),
+			RenderedOnly:   diff.RenderedOnly,
+			StaticOnly:     diff.StaticOnly,
+			Common:         diff.Common,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(records); err != nil {
+		log.Printf("Error exporting data to JSON: %v", err)
+		return err
+	}
+	return nil
+}
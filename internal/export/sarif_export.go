@@ -0,0 +1,225 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "dead-link-hunter"
+	toolURI      = "https://github.com/yingtu35/dead-link-hunter"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	BaselineState       string             `json:"baselineState,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifRules are the rules reported by the SARIF exporter, one per failure type
+var sarifRules = []sarifRule{
+	newSarifRule("DLH404", "NotFound", "Link target was not found (404 or 410)", "error"),
+	newSarifRule("DLH4XX", "ClientError", "Link target returned a client error", "error"),
+	newSarifRule("DLH5XX", "ServerError", "Link target returned a server error", "error"),
+	newSarifRule("DLH3XX", "Redirect", "Link target returned a redirect that was not followed", "warning"),
+	newSarifRule("DLHTIMEOUT", "Timeout", "Request to the link target timed out", "warning"),
+	newSarifRule("DLHREQUEST", "RequestError", "Request to the link target failed without a response", "warning"),
+	newSarifRule("DLHFILE", "MissingFile", "Linked file does not exist", "error"),
+	newSarifRule("DLHANCHOR", "MissingAnchor", "Linked anchor does not exist", "error"),
+	newSarifRule("DLHINVALID", "InvalidLink", "Link is malformed or references an undefined label", "error"),
+}
+
+func newSarifRule(id, name, description, level string) sarifRule {
+	return sarifRule{
+		ID:                   id,
+		Name:                 name,
+		ShortDescription:     sarifMessage{Text: description},
+		DefaultConfiguration: sarifConfiguration{Level: level},
+	}
+}
+
+// sarifRuleID returns the ID of the rule a dead link violates
+func sarifRuleID(deadLink webscraper.DeadLink) string {
+	switch deadLink.Category() {
+	case webscraper.CategoryClientError:
+		if deadLink.StatusCode == 404 || deadLink.StatusCode == 410 {
+			return "DLH404"
+		}
+		return "DLH4XX"
+	case webscraper.CategoryServerError:
+		return "DLH5XX"
+	case webscraper.CategoryRedirect:
+		return "DLH3XX"
+	case webscraper.CategoryTimeout:
+		return "DLHTIMEOUT"
+	case webscraper.CategoryMissingFile:
+		return "DLHFILE"
+	case webscraper.CategoryMissingAnchor:
+		return "DLHANCHOR"
+	case webscraper.CategoryInvalidLink:
+		return "DLHINVALID"
+	}
+	return "DLHREQUEST"
+}
+
+// sarifRuleIndex returns the index of the rule with the given ID in sarifRules
+func sarifRuleIndex(id string) int {
+	for i, rule := range sarifRules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+type SARIFExporter struct{}
+
+func NewSARIFExporter() Exporter {
+	return &SARIFExporter{}
+}
+
+func (e *SARIFExporter) Export(data *map[string]*webscraper.Page, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e.transformData(data)); err != nil {
+		log.Printf("Error exporting data to SARIF: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (e *SARIFExporter) transformData(data *map[string]*webscraper.Page) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           toolName,
+			InformationURI: toolURI,
+			Rules:          sarifRules,
+		}},
+		Results: []sarifResult{},
+	}
+
+	for _, url := range webscraper.PagesWithAnyDeadLinks(*data) {
+		page := (*data)[url]
+		for _, deadLink := range page.DeadLinks {
+			run.Results = append(run.Results, newSarifResult(url, deadLink))
+		}
+		for _, deadLink := range page.Suppressed {
+			run.Results = append(run.Results, newSarifResult(url, deadLink))
+		}
+	}
+
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+func newSarifResult(url string, deadLink webscraper.DeadLink) sarifResult {
+	ruleIndex := sarifRuleIndex(sarifRuleID(deadLink))
+	rule := sarifRules[ruleIndex]
+
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: url}},
+	}
+	if deadLink.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: deadLink.Line, StartColumn: deadLink.Column}
+	}
+	if deadLink.Selector != "" {
+		location.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: deadLink.Selector, Kind: "element"}}
+	}
+
+	result := sarifResult{
+		RuleID:    rule.ID,
+		RuleIndex: ruleIndex,
+		Level:     rule.DefaultConfiguration.Level,
+		Message:   sarifMessage{Text: "Dead link " + deadLink.URL + ": " + deadLink.Reason},
+		Locations: []sarifLocation{location},
+		// Lets code scanning track the same dead link across runs even when lines move
+		PartialFingerprints: map[string]string{"deadLink/v1": url + "|" + deadLink.URL},
+	}
+	switch deadLink.Baseline {
+	case webscraper.BaselineNew:
+		result.BaselineState = "new"
+	case webscraper.BaselineExisting:
+		result.BaselineState = "unchanged"
+	}
+	// Suppressed results are kept so code scanning shows them as dismissed
+	if deadLink.Suppression != nil {
+		result.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted", Justification: deadLink.Suppression.String()}}
+	}
+	return result
+}
@@ -0,0 +1,283 @@
+package markdown
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind describes how a link is written in the Markdown source
+type Kind string
+
+const (
+	KindInline    Kind = "inline"    // [text](target)
+	KindImage     Kind = "image"     // ![alt](target)
+	KindReference Kind = "reference" // [text][label] resolved through a [label]: target definition
+	KindAutolink  Kind = "autolink"  // <https://example.com> or a bare https://example.com
+)
+
+type Link struct {
+	Kind   Kind   // How the link is written
+	Target string // The link destination, empty for an undefined reference
+	Label  string // The reference label, only set for reference links
+	Line   int    // The 1-based line of the link in the document
+	Column int    // The 1-based byte column of the link in the document
+}
+
+type Document struct {
+	Links   []Link          // All links found in the document, in source order
+	Anchors map[string]bool // All anchors defined by headings and HTML ids
+}
+
+var (
+	fencePattern      = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	atxPattern        = regexp.MustCompile(`^ {0,3}#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextPattern     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*(?:<([^>]*)>|(\S+))`)
+	codeSpanPattern   = regexp.MustCompile("`+[^`]*`+")
+	inlinePattern     = regexp.MustCompile(`!?\[[^\[\]]*\]\((?:<([^>]*)>|([^()\s]*(?:\([^()\s]*\)[^()\s]*)*))(?:\s+(?:"[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+	fullRefPattern    = regexp.MustCompile(`!?\[([^\[\]]+)\]\[([^\[\]]*)\]`)
+	shortRefPattern   = regexp.MustCompile(`!?\[([^\[\]]+)\]`)
+	autolinkPattern   = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	bareURLPattern    = regexp.MustCompile(`https?://[^\s<>]+`)
+	htmlIDPattern     = regexp.MustCompile(`<[a-zA-Z][^>]*\s(?:id|name)=["']([^"']+)["']`)
+	inlineLinkText    = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+)
+
+// Parse reads a Markdown document and returns its links and anchors.
+// Links inside fenced code blocks, code spans and HTML comments are ignored.
+func Parse(r io.Reader) (*Document, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	masked, fenced := maskCode(lines)
+
+	doc := &Document{Anchors: make(map[string]bool)}
+	definitions := make(map[string]string)
+	isDefinition := make([]bool, len(lines))
+	for i, line := range masked {
+		m := definitionPattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		isDefinition[i] = true
+		label := normalizeLabel(line[m[2]:m[3]])
+		if _, ok := definitions[label]; ok {
+			// The first definition wins
+			continue
+		}
+		if m[4] >= 0 {
+			definitions[label] = line[m[4]:m[5]]
+		} else {
+			definitions[label] = line[m[6]:m[7]]
+		}
+		doc.Links = append(doc.Links, Link{Kind: KindReference, Target: definitions[label], Label: label, Line: i + 1, Column: m[0] + 1})
+	}
+
+	slugs := make(map[string]int)
+	for i, line := range masked {
+		if fenced[i] || isDefinition[i] {
+			continue
+		}
+		// Headings keep their code spans, GitHub includes them in the slug
+		if heading, ok := headingText(lines, i); ok {
+			doc.Anchors[uniqueSlug(slugs, Slug(heading))] = true
+		}
+		for _, m := range htmlIDPattern.FindAllStringSubmatch(line, -1) {
+			doc.Anchors[m[1]] = true
+		}
+		doc.Links = append(doc.Links, parseLine(line, i+1, definitions)...)
+	}
+	return doc, nil
+}
+
+// parseLine returns the links written on a single line. Every match is
+// masked once found so nested constructs such as [![alt](img)](target)
+// and bare URLs inside link targets are only reported once.
+func parseLine(line string, lineNo int, definitions map[string]string) []Link {
+	var links []Link
+	buf := []byte(line)
+
+	for {
+		m := inlinePattern.FindSubmatchIndex(buf)
+		if m == nil {
+			break
+		}
+		kind := KindInline
+		if buf[m[0]] == '!' {
+			kind = KindImage
+		}
+		target := ""
+		if m[2] >= 0 {
+			target = line[m[2]:m[3]]
+		} else {
+			target = line[m[4]:m[5]]
+		}
+		links = append(links, Link{Kind: kind, Target: target, Line: lineNo, Column: m[0] + 1})
+		mask(buf, m[0], m[1])
+	}
+
+	for _, m := range fullRefPattern.FindAllSubmatchIndex(buf, -1) {
+		label := string(buf[m[4]:m[5]])
+		if label == "" {
+			// Collapsed reference: [label][]
+			label = string(buf[m[2]:m[3]])
+		}
+		label = normalizeLabel(label)
+		links = append(links, Link{Kind: KindReference, Target: definitions[label], Label: label, Line: lineNo, Column: m[0] + 1})
+		mask(buf, m[0], m[1])
+	}
+
+	for _, m := range shortRefPattern.FindAllSubmatchIndex(buf, -1) {
+		// A shortcut reference without a definition is plain text
+		label := normalizeLabel(string(buf[m[2]:m[3]]))
+		if target, ok := definitions[label]; ok {
+			links = append(links, Link{Kind: KindReference, Target: target, Label: label, Line: lineNo, Column: m[0] + 1})
+			mask(buf, m[0], m[1])
+		}
+	}
+
+	for _, m := range autolinkPattern.FindAllSubmatchIndex(buf, -1) {
+		links = append(links, Link{Kind: KindAutolink, Target: string(buf[m[2]:m[3]]), Line: lineNo, Column: m[0] + 1})
+		mask(buf, m[0], m[1])
+	}
+
+	for _, m := range bareURLPattern.FindAllIndex(buf, -1) {
+		target := trimTrailingPunctuation(string(buf[m[0]:m[1]]))
+		links = append(links, Link{Kind: KindAutolink, Target: target, Line: lineNo, Column: m[0] + 1})
+	}
+	return links
+}
+
+// maskCode blanks out fenced code blocks, code spans and HTML comments
+// while keeping line and column positions intact. It also reports which
+// lines belong to a fenced code block.
+func maskCode(lines []string) ([]string, []bool) {
+	masked := make([]string, len(lines))
+	fenced := make([]bool, len(lines))
+	fence := ""
+	inComment := false
+	for i, line := range lines {
+		if fence != "" {
+			fenced[i] = true
+			if m := fencePattern.FindStringSubmatch(line); m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			fenced[i] = true
+			fence = m[1]
+			continue
+		}
+
+		buf := []byte(line)
+		pos := 0
+		for pos < len(buf) {
+			if inComment {
+				end := strings.Index(string(buf[pos:]), "-->")
+				if end < 0 {
+					mask(buf, pos, len(buf))
+					break
+				}
+				mask(buf, pos, pos+end+3)
+				pos += end + 3
+				inComment = false
+				continue
+			}
+			start := strings.Index(string(buf[pos:]), "<!--")
+			if start < 0 {
+				break
+			}
+			pos += start
+			inComment = true
+		}
+		for _, m := range codeSpanPattern.FindAllIndex(buf, -1) {
+			mask(buf, m[0], m[1])
+		}
+		masked[i] = string(buf)
+	}
+	return masked, fenced
+}
+
+// headingText returns the text of the heading on line i, if any
+func headingText(lines []string, i int) (string, bool) {
+	if m := atxPattern.FindStringSubmatch(lines[i]); m != nil {
+		return m[1], true
+	}
+	if i+1 < len(lines) && strings.TrimSpace(lines[i]) != "" && setextPattern.MatchString(lines[i+1]) {
+		// A "---" line after a list item or a blank line is a thematic break
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "- ") && !strings.HasPrefix(trimmed, "* ") && !setextPattern.MatchString(lines[i]) {
+			return trimmed, true
+		}
+	}
+	return "", false
+}
+
+// Slug returns the anchor GitHub generates for a heading: the text is
+// lowercased, punctuation is dropped and spaces become hyphens
+func Slug(heading string) string {
+	heading = inlineLinkText.ReplaceAllString(heading, "$1")
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// uniqueSlug appends -1, -2, ... to repeated slugs the way GitHub does,
+// skipping suffixes already taken by a heading such as "Usage 1"
+func uniqueSlug(seen map[string]int, slug string) string {
+	result := slug
+	for {
+		if _, ok := seen[result]; !ok {
+			break
+		}
+		seen[slug]++
+		result = slug + "-" + strconv.Itoa(seen[slug])
+	}
+	seen[result] = 0
+	return result
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func trimTrailingPunctuation(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		if strings.IndexByte(".,:;!?'\"*_~", last) >= 0 {
+			u = u[:len(u)-1]
+			continue
+		}
+		if last == ')' && strings.Count(u, "(") < strings.Count(u, ")") {
+			u = u[:len(u)-1]
+			continue
+		}
+		break
+	}
+	return u
+}
+
+func mask(buf []byte, start, end int) {
+	for i := start; i < end; i++ {
+		buf[i] = ' '
+	}
+}
@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    string
+	}{
+		{"Hello World", "hello-world"},
+		{"Hello, World!", "hello-world"},
+		{"  Trimmed  ", "trimmed"},
+		{"Foo & Bar", "foo--bar"},
+		{"snake_case and kebab-case", "snake_case-and-kebab-case"},
+		{"API `v2` (beta)", "api-v2-beta"},
+		{"See [the docs](docs.md) here", "see-the-docs-here"},
+		{"Ünïcödé Héading", "ünïcödé-héading"},
+		{"Version 1.2.3", "version-123"},
+		{"日本語", "日本語"},
+	}
+	for _, tt := range tests {
+		if got := Slug(tt.heading); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		slugs []string
+		want  []string
+	}{
+		{"distinct", []string{"a", "b"}, []string{"a", "b"}},
+		{"repeated", []string{"a", "a", "a"}, []string{"a", "a-1", "a-2"}},
+		{"suffix taken by a heading", []string{"a", "a-1", "a"}, []string{"a", "a-1", "a-2"}},
+		{"repeated suffixed heading", []string{"a", "a", "a-1"}, []string{"a", "a-1", "a-1-1"}},
+	}
+	for _, tt := range tests {
+		seen := make(map[string]int)
+		var got []string
+		for _, slug := range tt.slugs {
+			got = append(got, uniqueSlug(seen, slug))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseAnchors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"atx", "# Title\n## Sub Title ##\n", []string{"title", "sub-title"}},
+		{"setext", "Title\n=====\n\nSub\n---\n", []string{"title", "sub"}},
+		{"duplicates", "# Usage\n# Usage\n## Usage\n", []string{"usage", "usage-1", "usage-2"}},
+		{"code span kept", "# The `go` command\n", []string{"the-go-command"}},
+		{"html ids", "<a id=\"custom\"></a>\n<div name='other'>\n", []string{"custom", "other"}},
+		{"fenced heading ignored", "```\n# Not a heading\n```\n# Real\n", []string{"real"}},
+		{"list item before dashes", "- item\n---\n", nil},
+	}
+	for _, tt := range tests {
+		doc, err := Parse(strings.NewReader(tt.source))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(doc.Anchors) != len(tt.want) {
+			t.Errorf("%s: got anchors %v, want %v", tt.name, doc.Anchors, tt.want)
+			continue
+		}
+		for _, anchor := range tt.want {
+			if !doc.Anchors[anchor] {
+				t.Errorf("%s: missing anchor %q in %v", tt.name, anchor, doc.Anchors)
+			}
+		}
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Link
+	}{
+		{
+			"inline and image",
+			"See [docs](docs.md) and ![logo](img/logo.png \"Logo\").",
+			[]Link{
+				{Kind: KindInline, Target: "docs.md", Line: 1, Column: 5},
+				{Kind: KindImage, Target: "img/logo.png", Line: 1, Column: 25},
+			},
+		},
+		{
+			"angle brackets and parentheses",
+			"[a](<with space.md>) [b](https://en.wikipedia.org/wiki/Go_(language))",
+			[]Link{
+				{Kind: KindInline, Target: "with space.md", Line: 1, Column: 1},
+				{Kind: KindInline, Target: "https://en.wikipedia.org/wiki/Go_(language)", Line: 1, Column: 22},
+			},
+		},
+		{
+			"nested image link",
+			"[![badge](badge.svg)](https://ci.example.com)",
+			[]Link{
+				{Kind: KindImage, Target: "badge.svg", Line: 1, Column: 2},
+				{Kind: KindInline, Target: "https://ci.example.com", Line: 1, Column: 1},
+			},
+		},
+		{
+			"autolinks",
+			"<https://a.example.com> and https://b.example.com/path.",
+			[]Link{
+				{Kind: KindAutolink, Target: "https://a.example.com", Line: 1, Column: 1},
+				{Kind: KindAutolink, Target: "https://b.example.com/path", Line: 1, Column: 29},
+			},
+		},
+		{
+			"code spans",
+			"`[no](link.md)` then [yes](yes.md) and ``https://no.example.com``",
+			[]Link{
+				{Kind: KindInline, Target: "yes.md", Line: 1, Column: 22},
+			},
+		},
+		{
+			"fenced code and comments",
+			"```md\n[no](fenced.md)\n```\n<!-- [no](comment.md)\n[still](no.md) -->[yes](yes.md)",
+			[]Link{
+				{Kind: KindInline, Target: "yes.md", Line: 5, Column: 19},
+			},
+		},
+		{
+			"references",
+			"[Full][Docs] [docs][] [Docs] [missing][nope] [plain]\n\n[docs]: https://docs.example.com\n[DOCS]: https://ignored.example.com\n[angle]: <with space.md>",
+			[]Link{
+				{Kind: KindReference, Target: "https://docs.example.com", Label: "docs", Line: 3, Column: 1},
+				{Kind: KindReference, Target: "with space.md", Label: "angle", Line: 5, Column: 1},
+				{Kind: KindReference, Target: "https://docs.example.com", Label: "docs", Line: 1, Column: 1},
+				{Kind: KindReference, Target: "https://docs.example.com", Label: "docs", Line: 1, Column: 14},
+				{Kind: KindReference, Target: "", Label: "nope", Line: 1, Column: 30},
+				{Kind: KindReference, Target: "https://docs.example.com", Label: "docs", Line: 1, Column: 23},
+			},
+		},
+		{
+			"definition in code span",
+			"`[x]: https://no.example.com` [x]",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		doc, err := Parse(strings.NewReader(tt.source))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(doc.Links, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.name, doc.Links, tt.want)
+		}
+	}
+}
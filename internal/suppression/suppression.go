@@ -0,0 +1,143 @@
+package suppression
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+// dateLayout is the layout of expiry dates, e.g. 2026-12-31
+const dateLayout = "2006-01-02"
+
+// Rule acknowledges dead links matching all of its non-empty patterns
+type Rule struct {
+	URL     string `json:"url"`     // Glob pattern of the dead link, * matches any characters
+	Page    string `json:"page"`    // Glob pattern of the page containing the dead link
+	Status  string `json:"status"`  // Status code (403), status class (4xx) or failure category (timeout)
+	Owner   string `json:"owner"`   // Who is responsible for the suppression
+	Reason  string `json:"reason"`  // Why the dead link is acknowledged
+	Expires string `json:"expires"` // Date after which the rule no longer applies, optional
+
+	expires time.Time
+}
+
+type file struct {
+	Suppressions []*Rule `json:"suppressions"`
+}
+
+// Load reads and validates a suppression file. Unknown keys are errors so a
+// misspelled key doesn't silently widen or disable a rule.
+func Load(path string) ([]*Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+	for i, rule := range f.Suppressions {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("suppression %d: %w", i+1, err)
+		}
+	}
+	return f.Suppressions, nil
+}
+
+func (r *Rule) compile() error {
+	if r.URL == "" && r.Page == "" && r.Status == "" {
+		return errors.New("at least one of url, page or status is required")
+	}
+	if r.Owner == "" || r.Reason == "" {
+		return errors.New("owner and reason are required")
+	}
+	if r.Expires != "" {
+		expires, err := time.Parse(dateLayout, r.Expires)
+		if err != nil {
+			return fmt.Errorf("invalid expires date %q, expected YYYY-MM-DD", r.Expires)
+		}
+		// The rule applies until the end of the expiry day
+		r.expires = expires.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// Expired reports whether the rule no longer applies at the given time
+func (r *Rule) Expired(now time.Time) bool {
+	return !r.expires.IsZero() && !now.Before(r.expires)
+}
+
+// Matches reports whether the rule matches a dead link found on page
+func (r *Rule) Matches(page string, deadLink webscraper.DeadLink) bool {
+	if r.URL != "" && !webscraper.MatchGlob(r.URL, deadLink.URL) {
+		return false
+	}
+	if r.Page != "" && !webscraper.MatchGlob(r.Page, page) {
+		return false
+	}
+	return r.Status == "" || matchesStatus(r.Status, deadLink)
+}
+
+// Match returns the suppression of the first active rule matching the dead link, or nil
+func Match(rules []*Rule, page string, deadLink webscraper.DeadLink, now time.Time) *webscraper.Suppression {
+	for _, rule := range rules {
+		if rule.Matches(page, deadLink) && !rule.Expired(now) {
+			return &webscraper.Suppression{Owner: rule.Owner, Reason: rule.Reason, Expires: rule.Expires}
+		}
+	}
+	return nil
+}
+
+// Apply moves the dead links matching an active rule to the suppressed links of
+// their page and returns how many were suppressed. Expired rules are logged so
+// their dead links are noticed again.
+func Apply(rules []*Rule, pages map[string]*webscraper.Page, now time.Time) int {
+	for _, rule := range rules {
+		if rule.Expired(now) {
+			log.Printf("Suppression owned by %s expired on %s, its dead links are reported again", rule.Owner, rule.Expires)
+		}
+	}
+
+	count := 0
+	for url, page := range pages {
+		var deadLinks []webscraper.DeadLink
+		for _, deadLink := range page.DeadLinks {
+			if suppression := Match(rules, url, deadLink, now); suppression != nil {
+				deadLink.Suppression = suppression
+				page.Suppressed = append(page.Suppressed, deadLink)
+				count++
+				continue
+			}
+			deadLinks = append(deadLinks, deadLink)
+		}
+		if deadLinks == nil {
+			deadLinks = []webscraper.DeadLink{}
+		}
+		page.DeadLinks = deadLinks
+		page.DeadLinkCount = len(deadLinks)
+	}
+	return count
+}
+
+// matchesStatus matches a status code such as 403, a class such as 4xx or a category such as timeout
+func matchesStatus(status string, deadLink webscraper.DeadLink) bool {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if code, err := strconv.Atoi(status); err == nil {
+		return deadLink.StatusCode == code
+	}
+	if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+		return deadLink.StatusCode/100 == int(status[0]-'0')
+	}
+	return status == string(deadLink.Category())
+}
@@ -0,0 +1,169 @@
+package suppression
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/webscraper"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+		want    int
+	}{
+		{"valid", `{"suppressions": [{"url": "https://partner.example.com/*", "status": "403", "owner": "web", "reason": "Blocks crawlers", "expires": "2026-12-31"}]}`, "", 1},
+		{"empty", `{"suppressions": []}`, "", 0},
+		{"unknown rule key", `{"suppressions": [{"urls": "https://partner.example.com/*", "owner": "web", "reason": "r"}]}`, `unknown field "urls"`, 0},
+		{"unknown top level key", `{"suppression": []}`, `unknown field "suppression"`, 0},
+		{"no pattern", `{"suppressions": [{"owner": "web", "reason": "r"}]}`, "at least one of url, page or status", 0},
+		{"no owner", `{"suppressions": [{"url": "*", "reason": "r"}]}`, "owner and reason are required", 0},
+		{"invalid date", `{"suppressions": [{"url": "*", "owner": "web", "reason": "r", "expires": "31/12/2026"}]}`, "invalid expires date", 0},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "suppressions.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		rules, err := Load(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(rules) != tt.want {
+			t.Errorf("%s: got %d rules, want %d", tt.name, len(rules), tt.want)
+		}
+	}
+}
+
+func TestMatchesStatus(t *testing.T) {
+	tests := []struct {
+		status   string
+		deadLink webscraper.DeadLink
+		want     bool
+	}{
+		{"403", webscraper.DeadLink{StatusCode: 403}, true},
+		{"403", webscraper.DeadLink{StatusCode: 404}, false},
+		{"4xx", webscraper.DeadLink{StatusCode: 404}, true},
+		{"4XX", webscraper.DeadLink{StatusCode: 451}, true},
+		{"4xx", webscraper.DeadLink{StatusCode: 500}, false},
+		{"5xx", webscraper.DeadLink{StatusCode: 503}, true},
+		{"6xx", webscraper.DeadLink{StatusCode: 600}, false},
+		{"timeout", webscraper.DeadLink{Reason: webscraper.ReasonTimeout}, true},
+		{" Timeout ", webscraper.DeadLink{Reason: webscraper.ReasonTimeout}, true},
+		{"request error", webscraper.DeadLink{Reason: "connection refused"}, true},
+		{"missing anchor", webscraper.DeadLink{Reason: webscraper.ReasonAnchorNotFound}, true},
+		{"missing anchor", webscraper.DeadLink{Reason: webscraper.ReasonFileNotFound}, false},
+		{"client error", webscraper.DeadLink{StatusCode: 410}, true},
+	}
+	for _, tt := range tests {
+		if got := matchesStatus(tt.status, tt.deadLink); got != tt.want {
+			t.Errorf("matchesStatus(%q, %+v) = %v, want %v", tt.status, tt.deadLink, got, tt.want)
+		}
+	}
+}
+
+// rules compiles rules like Load
+func rules(t *testing.T, rules ...*Rule) []*Rule {
+	t.Helper()
+	for _, rule := range rules {
+		if err := rule.compile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rules
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := rules(t,
+		&Rule{URL: "https://expired.example.com/*", Owner: "old", Reason: "expired", Expires: "2026-05-31"},
+		&Rule{URL: "https://partner.example.com/*", Status: "403", Owner: "web", Reason: "Blocks crawlers"},
+		&Rule{Page: "https://example.com/blog/*", Owner: "blog", Reason: "Old posts", Expires: "2026-06-01"},
+		&Rule{URL: "https://*.example.com/*", Owner: "catch", Reason: "Everything else"},
+	)
+	tests := []struct {
+		name      string
+		page      string
+		deadLink  webscraper.DeadLink
+		wantOwner string
+	}{
+		{"url and status", "https://example.com/", webscraper.DeadLink{URL: "https://partner.example.com/a", StatusCode: 403}, "web"},
+		{"status mismatch falls through", "https://example.com/", webscraper.DeadLink{URL: "https://partner.example.com/a", StatusCode: 404}, "catch"},
+		{"page rule on its expiry day", "https://example.com/blog/1", webscraper.DeadLink{URL: "https://other.test/"}, "blog"},
+		{"expired rule skipped", "https://example.com/", webscraper.DeadLink{URL: "https://expired.example.com/a"}, "catch"},
+		{"no match", "https://example.com/", webscraper.DeadLink{URL: "https://other.test/"}, ""},
+	}
+	for _, tt := range tests {
+		suppression := Match(all, tt.page, tt.deadLink, now)
+		owner := ""
+		if suppression != nil {
+			owner = suppression.Owner
+		}
+		if owner != tt.wantOwner {
+			t.Errorf("%s: suppressed by %q, want %q", tt.name, owner, tt.wantOwner)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	rule := rules(t, &Rule{URL: "*", Owner: "web", Reason: "r", Expires: "2026-05-31"})[0]
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2026, 5, 31, 23, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := rule.Expired(tt.now); got != tt.want {
+			t.Errorf("Expired(%s) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	pages := map[string]*webscraper.Page{
+		"https://example.com/": {
+			DeadLinkCount: 3,
+			DeadLinks: []webscraper.DeadLink{
+				{URL: "https://partner.example.com/a", StatusCode: 403},
+				{URL: "https://example.com/missing", StatusCode: 404},
+				{URL: "https://partner.example.com/b", StatusCode: 403},
+			},
+		},
+		"https://example.com/all-known": {
+			DeadLinkCount: 1,
+			DeadLinks:     []webscraper.DeadLink{{URL: "https://partner.example.com/c", StatusCode: 403}},
+		},
+	}
+	count := Apply(rules(t, &Rule{URL: "https://partner.example.com/*", Status: "4xx", Owner: "web", Reason: "Blocks crawlers"}), pages, now)
+	if count != 3 {
+		t.Errorf("suppressed %d dead links, want 3", count)
+	}
+
+	page := pages["https://example.com/"]
+	if page.DeadLinkCount != 1 || len(page.DeadLinks) != 1 || page.DeadLinks[0].URL != "https://example.com/missing" {
+		t.Errorf("reported dead links %+v, want only the missing page", page.DeadLinks)
+	}
+	if len(page.Suppressed) != 2 || page.Suppressed[0].Suppression == nil || page.Suppressed[0].Suppression.Owner != "web" {
+		t.Errorf("suppressed dead links %+v, want both partner links with the suppression", page.Suppressed)
+	}
+
+	known := pages["https://example.com/all-known"]
+	if known.DeadLinkCount != 0 || known.DeadLinks == nil || len(known.DeadLinks) != 0 {
+		t.Errorf("page with only suppressed links has dead links %#v and count %d", known.DeadLinks, known.DeadLinkCount)
+	}
+}
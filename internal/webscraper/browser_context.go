@@ -0,0 +1,158 @@
+package webscraper
+
+import (
+	"log"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ResourceTypes lists the resource types of the requests of a page
+var ResourceTypes = []string{"document", "stylesheet", "image", "media", "font", "script", "texttrack", "xhr", "fetch", "eventsource", "websocket", "manifest", "other"}
+
+// AnalyticsHosts are the domains of common analytics and tag managers, which
+// are blocked with their subdomains
+var AnalyticsHosts = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"doubleclick.net",
+	"facebook.net",
+	"hotjar.com",
+	"segment.com",
+	"segment.io",
+	"mixpanel.com",
+	"amplitude.com",
+	"clarity.ms",
+	"plausible.io",
+}
+
+// analyticsPatterns are the host patterns of AnalyticsHosts and their subdomains
+var analyticsPatterns = domainPatterns(AnalyticsHosts)
+
+// Blocking lists the requests dynamic pages don't make, to crawl faster
+type Blocking struct {
+	ResourceTypes []string // Resource types that are not loaded, e.g. image, font or media
+	Hosts         []string // Glob patterns of hosts that are not loaded, e.g. *.example-ads.com
+	Analytics     bool     // Don't load the hosts of AnalyticsHosts
+}
+
+// Emulation is the device dynamic pages are rendered on
+type Emulation struct {
+	Device    string // A Playwright device name, e.g. "iPhone 13", empty for desktop Chromium
+	Width     int    // The viewport width, 0 for the viewport of the device
+	Height    int    // The viewport height, 0 for the viewport of the device
+	UserAgent string // The user agent, empty for the user agent of the device
+}
+
+func (b Blocking) enabled() bool {
+	return len(b.ResourceTypes) > 0 || len(b.Hosts) > 0 || b.Analytics
+}
+
+// blocks reports whether the request is not loaded. The page itself is always loaded.
+func (b Blocking) blocks(request playwright.Request) bool {
+	if request.IsNavigationRequest() && request.Frame().ParentFrame() == nil {
+		return false
+	}
+	if slices.Contains(b.ResourceTypes, request.ResourceType()) {
+		return true
+	}
+	u, err := url.Parse(request.URL())
+	if err != nil {
+		return false
+	}
+	hosts := b.Hosts
+	if b.Analytics {
+		hosts = append(hosts[:len(hosts):len(hosts)], analyticsPatterns...)
+	}
+	for _, pattern := range hosts {
+		if MatchGlob(pattern, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// domainPatterns returns the host patterns matching the domains and their
+// subdomains but not other hosts ending with them
+func domainPatterns(domains []string) []string {
+	patterns := make([]string, 0, 2*len(domains))
+	for _, domain := range domains {
+		patterns = append(patterns, domain, "*."+domain)
+	}
+	return patterns
+}
+
+// newBrowserContext returns a browser context emulating the device, with the
+// headers, cookies and host credentials of the options, starting from a logged
+// in session if any
+func newBrowserContext(browser playwright.Browser, options *ScraperOptions, device *playwright.DeviceDescriptor, storageState *playwright.OptionalStorageState) (playwright.BrowserContext, error) {
+	contextOptions := playwright.BrowserNewContextOptions{
+		ExtraHttpHeaders: options.Headers,
+		StorageState:     storageState,
+	}
+	if device != nil {
+		contextOptions.UserAgent = playwright.String(device.UserAgent)
+		contextOptions.Viewport = device.Viewport
+		contextOptions.Screen = device.Screen
+		contextOptions.DeviceScaleFactor = playwright.Float(device.DeviceScaleFactor)
+		contextOptions.IsMobile = playwright.Bool(device.IsMobile)
+		contextOptions.HasTouch = playwright.Bool(device.HasTouch)
+	}
+	if emulation := options.Emulation; emulation.Width > 0 && emulation.Height > 0 {
+		contextOptions.Viewport = &playwright.Size{Width: emulation.Width, Height: emulation.Height}
+	}
+	if options.Emulation.UserAgent != "" {
+		contextOptions.UserAgent = playwright.String(options.Emulation.UserAgent)
+	}
+
+	context, err := browser.NewContext(contextOptions)
+	if err != nil {
+		return nil, err
+	}
+	// Navigations and waits for selectors share the request timeout
+	context.SetDefaultTimeout(float64((time.Duration(options.Timeout) * time.Second).Milliseconds()))
+
+	if len(options.Cookies) > 0 {
+		if err := context.AddCookies(browserCookies(options.Cookies)); err != nil {
+			context.Close()
+			return nil, err
+		}
+	}
+
+	if len(options.Auth) > 0 || options.Blocking.enabled() {
+		err := context.Route("**/*", func(route playwright.Route) {
+			request := route.Request()
+			if options.Blocking.blocks(request) {
+				if err := route.Abort("blockedbyclient"); err != nil {
+					log.Printf("Error blocking %s: %v", request.URL(), err)
+				}
+				return
+			}
+
+			// Credentials are only added to the requests of matching hosts, unlike
+			// the HttpCredentials of the context which apply to every host
+			u, err := url.Parse(request.URL())
+			if err != nil {
+				route.Fallback()
+				return
+			}
+			authorization := options.authorization(u.Hostname())
+			if authorization == "" {
+				route.Fallback()
+				return
+			}
+			headers := request.Headers()
+			headers["authorization"] = authorization
+			if err := route.Fallback(playwright.RouteFallbackOptions{Headers: headers}); err != nil {
+				log.Printf("Error authenticating %s: %v", u, err)
+			}
+		})
+		if err != nil {
+			context.Close()
+			return nil, err
+		}
+	}
+	return context, nil
+}
@@ -0,0 +1,31 @@
+package webscraper
+
+import "testing"
+
+func TestAnalyticsPatterns(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"google-analytics.com", true},
+		{"www.google-analytics.com", true},
+		{"region1.analytics.google-analytics.com", true},
+		{"static.hotjar.com", true},
+		{"notgoogle-analytics.com", false},
+		{"mygoogle-analytics.com", false},
+		{"google-analytics.com.example.com", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		got := false
+		for _, pattern := range analyticsPatterns {
+			if MatchGlob(pattern, tt.host) {
+				got = true
+				break
+			}
+		}
+		if got != tt.want {
+			t.Errorf("analytics patterns match %q = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
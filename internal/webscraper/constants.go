@@ -4,4 +4,8 @@ const (
 	MaxDepth       = 5  // maximum depth of the links to follow
 	MaxConcurrency = 20 // maximum number of concurrent requests
 	DefaultTimeout = 10
+	ContextReuse   = 50 // navigations of a browser context before it is replaced
+	LoadMoreClicks = 10 // maximum clicks of a "load more" button
+	ExpandTimeout  = 2  // seconds new content has to appear after a scroll or a click
+	RenderMinLinks = 3  // pages with fewer links are rendered in hybrid mode
 )
@@ -0,0 +1,119 @@
+package webscraper
+
+import (
+	"log"
+	"sync/atomic"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// pooledContext is a browser context with its page, reused across navigations
+type pooledContext struct {
+	context playwright.BrowserContext
+	page    playwright.Page
+	events  *pageEvents // The events of the page
+	uses    int         // The navigations made with the context
+	crashed atomic.Bool // Set when the page crashes
+}
+
+// healthy reports whether the page can still be navigated
+func (c *pooledContext) healthy() bool {
+	return !c.crashed.Load() && !c.page.IsClosed()
+}
+
+func (c *pooledContext) close() {
+	if err := c.context.Close(); err != nil {
+		log.Printf("Error closing browser context: %v", err)
+	}
+}
+
+// contextPool is a bounded pool of browser contexts. Creating a context per
+// page dominates the time of dynamic crawls, so contexts are reused until
+// they have made maxUses navigations, or crashed.
+type contextPool struct {
+	browser      playwright.Browser
+	options      *ScraperOptions
+	device       *playwright.DeviceDescriptor     // The device every context emulates, nil for none
+	storageState *playwright.OptionalStorageState // The session every context starts from
+	maxUses      int                              // The navigations of a context before it is replaced
+	idle         chan *pooledContext              // The contexts ready to be used
+}
+
+// newContextPool returns a pool keeping up to size idle contexts. The callers
+// bound the contexts in use at once.
+func newContextPool(browser playwright.Browser, options *ScraperOptions, device *playwright.DeviceDescriptor, storageState *playwright.OptionalStorageState, size int) *contextPool {
+	return &contextPool{
+		browser:      browser,
+		options:      options,
+		device:       device,
+		storageState: storageState,
+		maxUses:      max(options.ContextReuse, 1),
+		idle:         make(chan *pooledContext, size),
+	}
+}
+
+// get returns a healthy idle context, or a new one if there is none
+func (p *contextPool) get() (*pooledContext, error) {
+	for {
+		select {
+		case c := <-p.idle:
+			if c.healthy() {
+				return c, nil
+			}
+			c.close()
+		default:
+			return p.create()
+		}
+	}
+}
+
+func (p *contextPool) create() (*pooledContext, error) {
+	context, err := newBrowserContext(p.browser, p.options, p.device, p.storageState)
+	if err != nil {
+		return nil, err
+	}
+	page, err := context.NewPage()
+	if err != nil {
+		context.Close()
+		return nil, err
+	}
+
+	c := &pooledContext{context: context, page: page, events: &pageEvents{options: p.options}}
+	c.events.listen(page)
+	page.OnCrash(func(playwright.Page) {
+		c.crashed.Store(true)
+	})
+	return c, nil
+}
+
+// put gives a context back after a navigation. Contexts that are worn out,
+// unhealthy or don't fit in the pool are closed.
+func (p *contextPool) put(c *pooledContext) {
+	c.uses++
+	if c.uses >= p.maxUses || !c.healthy() {
+		c.close()
+		return
+	}
+	select {
+	case p.idle <- c:
+	default:
+		c.close()
+	}
+}
+
+// discard closes a context whose state can't be trusted, e.g. after a failed navigation
+func (p *contextPool) discard(c *pooledContext) {
+	c.close()
+}
+
+// close closes the idle contexts
+func (p *contextPool) close() {
+	for {
+		select {
+		case c := <-p.idle:
+			c.close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package webscraper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonCookie is a cookie of a JSON cookie file, as exported by browsers and
+// their extensions
+type jsonCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Secure         bool    `json:"secure"`
+	HttpOnly       bool    `json:"httpOnly"`
+	Expires        float64 `json:"expires"`        // Unix time in seconds, Playwright's name
+	ExpirationDate float64 `json:"expirationDate"` // Unix time in seconds, browser extensions' name
+}
+
+// LoadCookies reads a cookie file, either a JSON array of cookies or a
+// Netscape cookies.txt file as written by curl and wget
+func LoadCookies(path string) ([]*http.Cookie, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseJSONCookies(trimmed)
+	}
+	return parseNetscapeCookies(content)
+}
+
+func parseJSONCookies(content []byte) ([]*http.Cookie, error) {
+	var jsonCookies []jsonCookie
+	if err := json.Unmarshal(content, &jsonCookies); err != nil {
+		return nil, err
+	}
+
+	cookies := make([]*http.Cookie, 0, len(jsonCookies))
+	for i, c := range jsonCookies {
+		if c.Name == "" || c.Domain == "" {
+			return nil, fmt.Errorf("cookie %d: name and domain are required", i+1)
+		}
+		cookie := &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure, HttpOnly: c.HttpOnly}
+		// Session cookies have no or a negative expiry
+		if expires := max(c.Expires, c.ExpirationDate); expires > 0 {
+			cookie.Expires = time.Unix(int64(expires), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// parseNetscapeCookies parses the tab separated lines of a cookies.txt file:
+// domain, include subdomains, path, secure, expiry, name and value. Cookies
+// that include subdomains get a domain with a leading dot, the others only
+// match their host.
+func parseNetscapeCookies(content []byte) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		// Only trim the line ending, an empty value leaves a trailing tab
+		text := strings.TrimRight(scanner.Text(), "\r\n")
+		httpOnly := strings.HasPrefix(text, "#HttpOnly_")
+		if httpOnly {
+			text = strings.TrimPrefix(text, "#HttpOnly_")
+		}
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab separated fields, got %d", line, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", line, fields[4])
+		}
+
+		cookieDomain := strings.TrimPrefix(fields[0], ".")
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookieDomain = "." + cookieDomain
+		}
+		cookie := &http.Cookie{
+			Domain:   cookieDomain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, scanner.Err()
+}
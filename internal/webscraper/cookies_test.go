@@ -0,0 +1,119 @@
+package webscraper
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadCookies(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []*http.Cookie
+		wantErr bool
+	}{
+		{
+			name: "netscape",
+			content: "# Netscape HTTP Cookie File\n\n" +
+				".example.com\tTRUE\t/\tTRUE\t1893456000\tsession\tabc\n" +
+				"www.example.com\tFALSE\t/docs\tFALSE\t0\tlang\ten\n",
+			want: []*http.Cookie{
+				{Name: "session", Value: "abc", Domain: ".example.com", Path: "/", Secure: true, Expires: time.Unix(1893456000, 0)},
+				{Name: "lang", Value: "en", Domain: "www.example.com", Path: "/docs"},
+			},
+		},
+		{
+			name:    "netscape include subdomains without a dot",
+			content: "example.com\tTRUE\t/\tFALSE\t0\tid\t1\n",
+			want:    []*http.Cookie{{Name: "id", Value: "1", Domain: ".example.com", Path: "/"}},
+		},
+		{
+			name:    "netscape host only with a dot",
+			content: ".example.com\tFALSE\t/\tFALSE\t0\tid\t1\n",
+			want:    []*http.Cookie{{Name: "id", Value: "1", Domain: "example.com", Path: "/"}},
+		},
+		{
+			name:    "netscape empty value",
+			content: "example.com\tFALSE\t/\tFALSE\t0\tempty\t\n",
+			want:    []*http.Cookie{{Name: "empty", Domain: "example.com", Path: "/"}},
+		},
+		{
+			name:    "netscape http only and CRLF",
+			content: "#HttpOnly_.example.com\tTRUE\t/\tTRUE\t0\ttoken\tt\r\n# comment\r\n",
+			want:    []*http.Cookie{{Name: "token", Value: "t", Domain: ".example.com", Path: "/", Secure: true, HttpOnly: true}},
+		},
+		{
+			name:    "netscape missing field",
+			content: "example.com\tFALSE\t/\tFALSE\t0\tname\n",
+			wantErr: true,
+		},
+		{
+			name:    "netscape invalid expiry",
+			content: "example.com\tFALSE\t/\tFALSE\tsoon\tname\tvalue\n",
+			wantErr: true,
+		},
+		{
+			name: "json",
+			content: `[
+				{"name": "session", "value": "abc", "domain": ".example.com", "path": "/", "secure": true, "httpOnly": true, "expires": 1893456000},
+				{"name": "ext", "value": "", "domain": "example.com", "expirationDate": 1893456000.5},
+				{"name": "temp", "value": "x", "domain": "example.com", "expires": -1}
+			]`,
+			want: []*http.Cookie{
+				{Name: "session", Value: "abc", Domain: ".example.com", Path: "/", Secure: true, HttpOnly: true, Expires: time.Unix(1893456000, 0)},
+				{Name: "ext", Domain: "example.com", Expires: time.Unix(1893456000, 0)},
+				{Name: "temp", Value: "x", Domain: "example.com"},
+			},
+		},
+		{
+			name:    "json without domain",
+			content: `[{"name": "session", "value": "abc"}]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "cookies")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadCookies(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddCookiesDomains(t *testing.T) {
+	client := &http.Client{}
+	addCookies(client, []*http.Cookie{
+		{Name: "all", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "host", Value: "1", Domain: "example.com", Path: "/"},
+	})
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"http://example.com/", []string{"all", "host"}},
+		{"http://www.example.com/", []string{"all"}},
+		{"http://notexample.com/", nil},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		var got []string
+		for _, cookie := range client.Jar.Cookies(u) {
+			got = append(got, cookie.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got cookies %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
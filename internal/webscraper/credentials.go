@@ -0,0 +1,118 @@
+package webscraper
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// HostAuth authenticates the requests to the hosts matching a pattern
+type HostAuth struct {
+	Host     string // Glob pattern of host names, e.g. *.example.com
+	Username string // Basic auth user name
+	Password string // Basic auth password
+	Token    string // Bearer token, sent instead of basic auth if set
+}
+
+// authorization returns the Authorization header for a host, empty if no credentials match it
+func (o *ScraperOptions) authorization(host string) string {
+	for _, auth := range o.Auth {
+		if !MatchGlob(strings.ToLower(auth.Host), strings.ToLower(host)) {
+			continue
+		}
+		if auth.Token != "" {
+			return "Bearer " + auth.Token
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))
+	}
+	return ""
+}
+
+// credentialsTransport adds the headers and the host credentials of the
+// options to every request, redirects included
+type credentialsTransport struct {
+	base    http.RoundTripper
+	options *ScraperOptions
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.options.Headers {
+		req.Header.Set(name, value)
+	}
+	if authorization := t.options.authorization(req.URL.Hostname()); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// newClient returns an HTTP client with the timeout, headers, cookies and
+// host credentials of the options
+func newClient(options *ScraperOptions) *http.Client {
+	client := &http.Client{
+		Timeout: time.Duration(options.Timeout) * time.Second,
+	}
+	if len(options.Headers) > 0 || len(options.Auth) > 0 {
+		client.Transport = &credentialsTransport{base: http.DefaultTransport, options: options}
+	}
+	addCookies(client, options.Cookies)
+	return client
+}
+
+// addCookies adds cookies to the cookie jar of the client, creating it if needed
+func addCookies(client *http.Client, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+	if client.Jar == nil {
+		// cookiejar.New never fails without options
+		client.Jar, _ = cookiejar.New(nil)
+	}
+	for _, cookie := range cookies {
+		u := cookieURL(cookie)
+		// Like in browsers, a domain without a leading dot only matches its host
+		if !strings.HasPrefix(cookie.Domain, ".") {
+			hostOnly := *cookie
+			hostOnly.Domain = ""
+			cookie = &hostOnly
+		}
+		client.Jar.SetCookies(u, []*http.Cookie{cookie})
+	}
+}
+
+// cookieURL returns a URL the cookie can be set from
+func cookieURL(cookie *http.Cookie) *url.URL {
+	u := &url.URL{Scheme: "http", Host: strings.TrimPrefix(cookie.Domain, "."), Path: cookie.Path}
+	if cookie.Secure {
+		u.Scheme = "https"
+	}
+	return u
+}
+
+// browserCookies converts cookies for a browser context
+func browserCookies(cookies []*http.Cookie) []playwright.OptionalCookie {
+	result := make([]playwright.OptionalCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		browserCookie := playwright.OptionalCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   playwright.String(cookie.Domain),
+			Path:     playwright.String(cookie.Path),
+			HttpOnly: playwright.Bool(cookie.HttpOnly),
+			Secure:   playwright.Bool(cookie.Secure),
+		}
+		if cookie.Path == "" {
+			browserCookie.Path = playwright.String("/")
+		}
+		if !cookie.Expires.IsZero() {
+			browserCookie.Expires = playwright.Float(float64(cookie.Expires.Unix()))
+		}
+		result = append(result, browserCookie)
+	}
+	return result
+}
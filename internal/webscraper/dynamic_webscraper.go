@@ -9,27 +9,31 @@ import (
 	"time"
 
 	"github.com/playwright-community/playwright-go"
-	"github.com/rodaine/table"
 	"github.com/yingtu35/dead-link-hunter/pkg/domain"
 	"golang.org/x/sync/singleflight"
 )
 
 type DynamicHunter struct {
-	scraperOptions     *ScraperOptions        // The scraper options to use
-	pwClient           *playwright.Playwright // The Playwright client to use
-	browser            *playwright.Browser    // The Playwright browser to use
-	client             *http.Client           // The HTTP client to use
-	url                string                 // The URL to start the hunting
-	protocol           string                 // The protocol of the URL
-	domain             string                 // The domain of the URL
-	visitedPages       map[string]bool        // A map to keep track of visited pages
-	deadUrls           map[string]bool        // A map to keep track of dead URLs
-	pagesWithDeadLinks map[string]*Page       // A map to keep track of pages with dead links
+	scraperOptions *ScraperOptions        // The scraper options to use
+	pwClient       *playwright.Playwright // The Playwright client to use, nil unless the hunter is open
+	browser        *playwright.Browser    // The Playwright browser to use, nil unless the hunter is open
+	client         *http.Client           // The HTTP client to use
+	url            string                 // The URL to start the hunting
+	protocol       string                 // The protocol of the URL
+	domain         string                 // The domain of the URL
+	visitedPages   map[string]bool        // A map to keep track of visited pages
+	failedUrls     map[string]error       // A map to keep track of URLs that could not be fetched
+	deadUrls       map[string]int         // A map to keep track of dead URLs and their status codes
+	pages          map[string]*Page       // A map to keep track of crawled pages and their dead links
+
+	device       *playwright.DeviceDescriptor     // The device pages are rendered on, nil for desktop Chromium
+	storageState *playwright.OptionalStorageState // The session of the login, nil if there is none
+	pool         *contextPool                     // The browser contexts pages are opened in
 
 	semaphore chan struct{} // A semaphore to limit the number of concurrent requests
 
-	visitedMu sync.Mutex // A mutex to protect visitedPages and deadUrls
-	pageMu    sync.Mutex // A mutex to protect pagesWithDeadLinks
+	visitedMu sync.Mutex // A mutex to protect visitedPages, deadUrls and failedUrls
+	pageMu    sync.Mutex // A mutex to protect pages
 
 	flightGroup singleflight.Group // A singleflight group to avoid duplicate requests
 }
@@ -44,56 +48,35 @@ func NewDynamicHunter(url string) WebScraper {
 		log.Fatalf("Error getting domain from URL: %v", err)
 	}
 
-	var pwClient *playwright.Playwright
-	var browser *playwright.Browser
-	var client *http.Client
-
-	pwOptions := playwright.RunOptions{
-		SkipInstallBrowsers: true,
-	}
-
-	pw, err := playwright.Run(&pwOptions)
-	if err != nil {
-		log.Fatalf("Error creating Playwright client: %v", err)
-	}
-	pwClient = pw
-
-	browserInstance, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-		Headless: playwright.Bool(true),
-	})
-	if err != nil {
-		log.Fatalf("Error launching Playwright browser: %v", err)
-	}
-	browser = &browserInstance
-
-	client = &http.Client{
+	client := &http.Client{
 		Timeout: DefaultTimeout * time.Second,
 	}
 
 	semaphore := make(chan struct{})
 
 	return &DynamicHunter{
-		scraperOptions:     &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout},
-		pwClient:           pwClient,
-		browser:            browser,
-		client:             client,
-		url:                url,
-		protocol:           protocol,
-		domain:             domain,
-		visitedPages:       make(map[string]bool),
-		deadUrls:           make(map[string]bool),
-		pagesWithDeadLinks: make(map[string]*Page),
-		semaphore:          semaphore,
+		scraperOptions: &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout, ContextReuse: ContextReuse},
+		client:         client,
+		url:            url,
+		protocol:       protocol,
+		domain:         domain,
+		visitedPages:   make(map[string]bool),
+		deadUrls:       make(map[string]int),
+		failedUrls:     make(map[string]error),
+		pages:          make(map[string]*Page),
+		semaphore:      semaphore,
 	}
 }
 
 func (dh *DynamicHunter) SetHunterOptions(options *ScraperOptions) {
 	dh.scraperOptions = options
 	dh.semaphore = make(chan struct{}, dh.scraperOptions.MaxConcurrency)
-	dh.client.Timeout = time.Duration(dh.scraperOptions.Timeout) * time.Second
+	dh.client = newClient(dh.scraperOptions)
 }
 
 func (dh *DynamicHunter) StartHunting() {
+	dh.open()
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -111,7 +94,7 @@ func (dh *DynamicHunter) StartHunting() {
 		}
 
 		// Handle the result if needed
-		_, ok := val.(bool)
+		_, ok := val.(int)
 		if !ok {
 			log.Printf("Error type assertion for starting URL %s", dh.url)
 			return
@@ -121,44 +104,119 @@ func (dh *DynamicHunter) StartHunting() {
 
 	wg.Wait()
 
+	if dh.scraperOptions.Screenshots != "" {
+		dh.takeScreenshots()
+	}
 	dh.close()
 }
 
-func (dh *DynamicHunter) GetResults() *map[string]*Page {
-	return &dh.pagesWithDeadLinks
-}
+// open launches the browser, logs in if there is a login and opens the pool
+// of browser contexts. The browser only runs while the hunter is open so
+// hunters waiting for their turn don't keep one idle.
+func (dh *DynamicHunter) open() {
+	pwOptions := playwright.RunOptions{
+		SkipInstallBrowsers: true,
+	}
 
-func (dh *DynamicHunter) PrintResults() {
-	log.Println()
-	if len(dh.pagesWithDeadLinks) == 0 {
-		log.Println("No dead links found")
-		return
-	}
-	tbl := table.New("Page", "Counts", "Dead Links")
-	for url, page := range dh.pagesWithDeadLinks {
-		for i, deadLink := range page.DeadLinks {
-			if i == 0 {
-				tbl.AddRow(url, page.DeadLinkCount, deadLink)
-			} else {
-				tbl.AddRow("", "", deadLink)
-			}
+	pw, err := playwright.Run(&pwOptions)
+	if err != nil {
+		log.Fatalf("Error creating Playwright client: %v", err)
+	}
+	dh.pwClient = pw
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	})
+	if err != nil {
+		dh.close()
+		log.Fatalf("Error launching Playwright browser: %v", err)
+	}
+	dh.browser = &browser
+
+	if name := dh.scraperOptions.Emulation.Device; name != "" {
+		device, ok := dh.pwClient.Devices[name]
+		if !ok {
+			dh.close()
+			log.Fatalf("Unknown device %q, see the device names of Playwright", name)
+		}
+		dh.device = device
+	}
+
+	// Crawling without the session would report every page behind the login as dead
+	if dh.scraperOptions.Login != nil {
+		if err := dh.login(); err != nil {
+			dh.close()
+			log.Fatalf("Error logging in at %s: %v", dh.scraperOptions.Login.URL, err)
 		}
 	}
-	tbl.Print()
+
+	dh.pool = newContextPool(*dh.browser, dh.scraperOptions, dh.device, dh.storageState, dh.scraperOptions.MaxConcurrency)
+}
+
+// pageLinks renders a page like hunt and returns its status code and links.
+// The status code is 0 if the page had no response.
+func (dh *DynamicHunter) pageLinks(url string) (int, []foundLink, error) {
+	pooled, err := dh.pool.get()
+	if err != nil {
+		return 0, nil, err
+	}
+	page := pooled.page
+
+	log.Printf("rendering page %s", url)
+	wait := dh.scraperOptions.waitStrategy(url)
+	resp, err := page.Goto(url, wait.gotoOptions())
+	if err != nil {
+		dh.pool.discard(pooled)
+		return 0, nil, &fetchError{err}
+	}
+	defer dh.pool.put(pooled)
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.Status()
+	}
+	if isDeadStatus(statusCode) {
+		return statusCode, nil, nil
+	}
+	wait.afterNavigation(page)
+	dh.scraperOptions.Expand.expand(page)
+	links, err := dh.getAllLinks(page)
+	return statusCode, links, err
+}
+
+// fetchFailed remembers that the URL could not be fetched and returns the error to report
+func (dh *DynamicHunter) fetchFailed(url string, err error) error {
+	fetchErr := &fetchError{err}
+	dh.visitedMu.Lock()
+	dh.failedUrls[url] = fetchErr
+	dh.visitedMu.Unlock()
+	return fetchErr
+}
+
+func (dh *DynamicHunter) GetResults() *map[string]*Page {
+	return &dh.pages
 }
 
 func (dh *DynamicHunter) close() {
-	if dh.pwClient != nil && dh.browser != nil {
+	if dh.pool != nil {
+		dh.pool.close()
+		dh.pool = nil
+	}
+	if dh.browser != nil {
 		if err := (*dh.browser).Close(); err != nil {
 			log.Fatalf("Error closing browser: %v", err)
 		}
+		dh.browser = nil
+	}
+	if dh.pwClient != nil {
 		if err := dh.pwClient.Stop(); err != nil {
 			log.Fatalf("Error stopping Playwright client: %v", err)
 		}
+		dh.pwClient = nil
 	}
 }
 
-func (dh *DynamicHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (bool, error) {
+func (dh *DynamicHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (int, error) {
 	dh.semaphore <- struct{}{}
 	defer func() {
 		<-dh.semaphore
@@ -167,15 +225,15 @@ func (dh *DynamicHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (boo
 	// Check if the URL has already been visited
 	dh.visitedMu.Lock()
 	if dh.visitedPages[url] {
-		isDead := dh.deadUrls[url]
+		statusCode, err := dh.deadUrls[url], dh.failedUrls[url]
 		dh.visitedMu.Unlock()
-		return isDead, nil
+		return statusCode, err
 	}
 	dh.visitedPages[url] = true
 	dh.visitedMu.Unlock()
 
 	if !domain.IsSameDomain(dh.domain, url) {
-		return false, nil
+		return 0, nil
 	}
 
 	// Check if it's a binary file URL
@@ -184,100 +242,178 @@ func (dh *DynamicHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (boo
 		log.Printf("fetching binary file %s", url)
 		resp, err := dh.client.Head(url)
 		if err != nil {
-			return false, err
+			return 0, dh.fetchFailed(url, err)
 		}
-		if resp.StatusCode > 299 {
+		if isDeadStatus(resp.StatusCode) {
 			dh.visitedMu.Lock()
-			dh.deadUrls[url] = true
+			dh.deadUrls[url] = resp.StatusCode
 			dh.visitedMu.Unlock()
-			return true, nil
 		}
-		return false, nil
-	}
-
-	// Create a new context and page
-	context, err := (*dh.browser).NewContext()
-	if err != nil {
-		return false, err
+		return resp.StatusCode, nil
 	}
-	defer context.Close()
 
-	context.SetDefaultNavigationTimeout(float64((DefaultTimeout * time.Second).Milliseconds()))
-	page, err := context.NewPage()
+	// Reuse a context and its page from the pool
+	pooled, err := dh.pool.get()
 	if err != nil {
-		return false, err
+		return 0, err
 	}
+	page := pooled.page
 
 	log.Printf("fetching dynamic page %s", url)
-	resp, err := page.Goto(url)
+	wait := dh.scraperOptions.waitStrategy(url)
+	pooled.events.start()
+	resp, err := page.Goto(url, wait.gotoOptions())
 	if err != nil {
-		return false, err
+		dh.pool.discard(pooled)
+		return 0, dh.fetchFailed(url, err)
 	}
+	defer dh.pool.put(pooled)
+	defer pooled.events.stop()
 
-	if resp.Status() > 299 {
+	// A reused page has no response when only the fragment of its URL changes
+	if resp != nil && isDeadStatus(resp.Status()) {
 		dh.visitedMu.Lock()
-		dh.deadUrls[url] = true
+		dh.deadUrls[url] = resp.Status()
 		dh.visitedMu.Unlock()
-		return true, nil
+		return resp.Status(), nil
 	}
+	// Requests keep failing until the page is done with, record them last
+	defer dh.addPageEvents(url, pooled.events)
+	wait.afterNavigation(page)
 
-	// Check if the current depth is greater than the maximum depth
-	if curDepth >= dh.scraperOptions.MaxDepth {
-		return false, nil
+	// Pages beyond the maximum depth or out of scope are checked but not crawled
+	if !dh.scraperOptions.crawls(url, curDepth) {
+		return 0, nil
 	}
 
-	links, err := page.Locator("a").All()
+	dh.scraperOptions.Expand.expand(page)
+	links, err := dh.getAllLinks(page)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
+	links = dh.scraperOptions.filterLinks(links)
+
+	dh.pageMu.Lock()
+	addCheckedLinks(dh.pages, url, sameDomainLinks(dh.domain, links))
+	dh.pageMu.Unlock()
+
 	for _, link := range links {
-		href, err := link.GetAttribute("href")
-		if err != nil {
-			return false, err
-		}
-		linkURL, err := dh.constructURL(href)
-		if err != nil {
-			continue
-		}
 		wg.Add(1)
-		go func(linkURL string) {
+		go func(link foundLink) {
 			// Decrement the wait group counter when the function returns
 			defer wg.Done()
 
-			val, err, _ := dh.flightGroup.Do(linkURL, func() (interface{}, error) {
-				return dh.hunt(linkURL, wg, curDepth+1)
+			val, err, _ := dh.flightGroup.Do(link.url, func() (interface{}, error) {
+				return dh.hunt(link.url, wg, curDepth+1)
 			})
-			if err != nil {
-				log.Printf("Error hunting %s: %v", linkURL, err)
-				return
-			}
-			isDead, ok := val.(bool)
-			if !ok {
-				log.Printf("Error type assertion %s", linkURL)
-				return
-			}
+			deadLink, isDead := toDeadLink(dh.scraperOptions, link, val, err)
 			if isDead {
-				// * Dead link found, add it to the pagesWithDeadLinks map
+				// * Dead link found, add it to the pages map
 				dh.pageMu.Lock()
-				dh.addDeadLink(DeadLinkMsg{url, linkURL})
+				addDeadLink(dh.pages, dh.scraperOptions, DeadLinkMsg{url, deadLink})
 				dh.pageMu.Unlock()
 			}
-		}(linkURL)
+		}(link)
+	}
+	return 0, nil
+}
+
+// addPageEvents records the failed subresource requests of a page as its dead links,
+// along with its console messages
+func (dh *DynamicHunter) addPageEvents(url string, events *pageEvents) {
+	failedRequests, messages := events.stop()
+	dh.pageMu.Lock()
+	defer dh.pageMu.Unlock()
+	for _, deadLink := range failedRequests {
+		addDeadLink(dh.pages, dh.scraperOptions, DeadLinkMsg{url, deadLink})
+	}
+	if len(messages) > 0 {
+		if _, ok := dh.pages[url]; !ok {
+			dh.pages[url] = newPage()
+		}
+		dh.pages[url].ConsoleMessages = append(dh.pages[url].ConsoleMessages, messages...)
 	}
-	return false, nil
 }
 
-func (dh *DynamicHunter) addDeadLink(deadlink DeadLinkMsg) {
-	parentUrl := deadlink.parentUrl
-	url := deadlink.url
-	if _, ok := dh.pagesWithDeadLinks[parentUrl]; parentUrl != "" && !ok {
-		dh.pagesWithDeadLinks[parentUrl] = &Page{
-			DeadLinkCount: 0,
-			DeadLinks:     []string{},
+// getAllLinks returns the links of the page, including those in open shadow
+// roots and in same-domain iframes
+func (dh *DynamicHunter) getAllLinks(page playwright.Page) ([]foundLink, error) {
+	links, err := dh.getFrameLinks(page.MainFrame())
+	if err != nil {
+		return nil, err
+	}
+	for _, frame := range page.Frames() {
+		if frame == page.MainFrame() || !domain.IsSameDomain(dh.domain, frame.URL()) {
+			continue
+		}
+		iframe, err := iframeSelector(frame)
+		if err != nil {
+			log.Printf("Error locating frame %s: %v", frame.URL(), err)
+			continue
+		}
+		frameLinks, err := dh.getFrameLinks(frame)
+		if err != nil {
+			// The frame may have been detached in the meantime
+			log.Printf("Error getting links of frame %s: %v", frame.URL(), err)
+			continue
+		}
+		for _, link := range frameLinks {
+			link.selector = frameSelector(iframe, link.selector)
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// iframeSelector returns the selector of the iframe element of a frame,
+// chained with the selectors of the iframes it is nested in
+func iframeSelector(frame playwright.Frame) (string, error) {
+	element, err := frame.FrameElement()
+	if err != nil {
+		return "", err
+	}
+	selector, err := element.Evaluate(cssSelectorScript)
+	if err != nil {
+		return "", err
+	}
+	iframe, _ := selector.(string)
+	if parent := frame.ParentFrame(); parent != nil && parent.ParentFrame() != nil {
+		parentSelector, err := iframeSelector(parent)
+		if err != nil {
+			return "", err
+		}
+		iframe = frameSelector(parentSelector, iframe)
+	}
+	return iframe, nil
+}
+
+// getFrameLinks returns the links of the document of a frame. Playwright's
+// CSS selectors pierce open shadow roots.
+func (dh *DynamicHunter) getFrameLinks(frame playwright.Frame) ([]foundLink, error) {
+	locators, err := frame.Locator("a").All()
+	if err != nil {
+		return nil, err
+	}
+
+	var links []foundLink
+	for _, locator := range locators {
+		href, err := locator.GetAttribute("href")
+		if err != nil {
+			return nil, err
+		}
+		linkURL, err := dh.constructURL(href)
+		if err != nil {
+			continue
+		}
+		selector, err := locator.Evaluate(cssSelectorScript, nil)
+		if err != nil {
+			return nil, err
 		}
+		link := foundLink{url: linkURL}
+		link.selector, _ = selector.(string)
+		links = append(links, link)
 	}
-	dh.pagesWithDeadLinks[parentUrl].DeadLinkCount++
-	dh.pagesWithDeadLinks[parentUrl].DeadLinks = append(dh.pagesWithDeadLinks[parentUrl].DeadLinks, url)
+	return links, nil
 }
 
 func (dh *DynamicHunter) constructURL(url string) (string, error) {
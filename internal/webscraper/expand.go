@@ -0,0 +1,58 @@
+package webscraper
+
+import (
+	"log"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Expansion loads the content of a dynamic page that only appears when it is
+// scrolled or when a "load more" button is clicked
+type Expansion struct {
+	Scrolls int    // Maximum scrolls to the bottom of the page, 0 disables scrolling
+	Click   string // Selector of a "load more" button, empty for none
+	Clicks  int    // Maximum clicks of the button
+}
+
+// expand scrolls the page until it stops growing, then clicks the button until
+// it disappears or no more links appear
+func (e Expansion) expand(page playwright.Page) {
+	timeout := float64((ExpandTimeout * time.Second).Milliseconds())
+	for i := 0; i < e.Scrolls; i++ {
+		height, err := page.Evaluate("document.documentElement.scrollHeight")
+		if err != nil {
+			log.Printf("Error scrolling %s: %v", page.URL(), err)
+			return
+		}
+		if _, err := page.Evaluate("window.scrollTo(0, document.documentElement.scrollHeight)"); err != nil {
+			log.Printf("Error scrolling %s: %v", page.URL(), err)
+			return
+		}
+		if _, err := page.WaitForFunction("height => document.documentElement.scrollHeight > height", height, playwright.PageWaitForFunctionOptions{Timeout: &timeout}); err != nil {
+			break
+		}
+	}
+
+	if e.Click == "" {
+		return
+	}
+	button := page.Locator(e.Click).First()
+	for i := 0; i < e.Clicks; i++ {
+		if visible, err := button.IsVisible(); err != nil || !visible {
+			return
+		}
+		links, err := page.Evaluate("document.querySelectorAll('a').length")
+		if err != nil {
+			log.Printf("Error counting the links of %s: %v", page.URL(), err)
+			return
+		}
+		if err := button.Click(); err != nil {
+			log.Printf("Error clicking %s on %s: %v", e.Click, page.URL(), err)
+			return
+		}
+		if _, err := page.WaitForFunction("links => document.querySelectorAll('a').length > links", links, playwright.PageWaitForFunctionOptions{Timeout: &timeout}); err != nil {
+			return
+		}
+	}
+}
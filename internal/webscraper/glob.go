@@ -0,0 +1,29 @@
+package webscraper
+
+// MatchGlob reports whether s matches the pattern, where * matches any
+// sequence of characters, including slashes, and every other character
+// matches itself
+func MatchGlob(pattern, s string) bool {
+	// Backtrack to the last star when a literal character doesn't match
+	p, i := 0, 0
+	star, next := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,35 @@
+package webscraper
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "https://example.com/a/b", true},
+		{"https://example.com", "https://example.com", true},
+		{"https://example.com", "https://example.com/", false},
+		{"*/docs/*", "https://example.com/docs/intro", true},
+		{"*/docs/*", "https://example.com/blog/docs", false},
+		{"*.pdf", "https://example.com/a/b.pdf", true},
+		{"*.pdf", "https://example.com/a.pdf/b", false},
+		{"https://*.example.com/*", "https://www.example.com/a", true},
+		{"https://*.example.com/*", "https://example.com/a", false},
+		{"*a*b*", "xxaxxbxx", true},
+		{"*a*b", "xxbxxa", false},
+		{"a**b", "ab", true},
+		{"*aab", "aaab", true},
+		{"?", "?", true},
+		{"?", "a", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package webscraper
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// spaRootIDs are the ids of the elements single page applications render into
+var spaRootIDs = []string{"root", "app", "__next", "__nuxt", "svelte"}
+
+// Escalation decides which pages fetched without JavaScript are rendered by
+// the browser in hybrid mode
+type Escalation struct {
+	Patterns []string // Glob patterns of the pages that are always rendered
+	MinLinks int      // Pages with fewer links are rendered, 0 disables the check
+	Markers  bool     // Render pages with an empty application root or a <noscript> warning
+}
+
+// NewHybridHunter returns a static hunter that renders the pages matching the
+// escalation of its options with a browser, so their links are those a
+// browser sees
+func NewHybridHunter(url string) WebScraper {
+	hunter := NewStaticHunter(url).(*StaticHunter)
+	hunter.renderer = NewDynamicHunter(url).(*DynamicHunter)
+	return hunter
+}
+
+// renderReason returns why a page fetched without JavaScript needs rendering,
+// or an empty string if its links can be trusted
+func (e Escalation) renderReason(url string, content []byte, links []foundLink) string {
+	for _, pattern := range e.Patterns {
+		if MatchGlob(pattern, url) {
+			return "matches " + pattern
+		}
+	}
+	if len(links) < e.MinLinks {
+		return fmt.Sprintf("%d links, fewer than %d", len(links), e.MinLinks)
+	}
+	if !e.Markers {
+		return ""
+	}
+
+	doc, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		return ""
+	}
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		if id := attr(n, "id"); slices.Contains(spaRootIDs, id) && strings.TrimSpace(nodeText(n)) == "" && !hasElementChild(n) {
+			return "empty application root #" + id
+		}
+		if n.Data == "noscript" && strings.Contains(strings.ToLower(nodeText(n)), "javascript") {
+			return "<noscript> warning"
+		}
+	}
+	return ""
+}
+
+// nodeText returns the text of a node. The parser keeps the content of
+// <noscript> as raw text as it assumes scripting is enabled.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for d := range n.Descendants() {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return b.String()
+}
+
+// renderLinks returns the links of the page rendered by the browser if the
+// escalation applies to it, or the links fetched without JavaScript
+func (d *StaticHunter) renderLinks(url string, content []byte, links []foundLink) []foundLink {
+	reason := d.scraperOptions.Escalation.renderReason(url, content, links)
+	if reason == "" {
+		return links
+	}
+	log.Printf("rendering %s: %s", url, reason)
+	statusCode, rendered, err := d.renderer.pageLinks(url)
+	if err != nil || isDeadStatus(statusCode) {
+		log.Printf("Error rendering %s, keeping the static links: status %d, %v", url, statusCode, err)
+		return links
+	}
+	return rendered
+}
+
+func hasElementChild(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return true
+		}
+	}
+	return false
+}
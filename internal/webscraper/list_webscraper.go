@@ -0,0 +1,140 @@
+package webscraper
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ListHunter checks a list of URLs without crawling them. The dead links are
+// recorded on a single page named after the source of the list.
+type ListHunter struct {
+	scraperOptions *ScraperOptions  // The scraper options to use
+	client         *http.Client     // The HTTP client to use
+	source         string           // Where the URLs come from, the page of their dead links
+	links          []foundLink      // The URLs to check with their position in the source
+	pages          map[string]*Page // A map to keep track of the dead links of the source
+
+	semaphore chan struct{} // A semaphore to limit the number of concurrent requests
+
+	pageMu sync.Mutex // A mutex to protect pages
+
+	flightGroup singleflight.Group // A singleflight group to avoid duplicate requests
+}
+
+func NewListHunter(source string, urls []string) WebScraper {
+	links := make([]foundLink, 0, len(urls))
+	for _, u := range urls {
+		links = append(links, foundLink{url: u})
+	}
+	return newListHunter(source, links)
+}
+
+// NewListHunterFromReader returns a hunter of the URLs read from r, one per
+// line. Blank lines and lines starting with # are skipped, and anything after
+// the first whitespace of a line is ignored so that exports with more columns
+// can be read. Dead links report the line and column of the URL in source.
+func NewListHunterFromReader(source string, r io.Reader) (WebScraper, error) {
+	var links []foundLink
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		links = append(links, foundLink{
+			url:    strings.Fields(trimmed)[0],
+			line:   line,
+			column: len(text) - len(trimmed) + 1,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newListHunter(source, links), nil
+}
+
+func newListHunter(source string, links []foundLink) *ListHunter {
+	client := &http.Client{
+		Timeout: DefaultTimeout * time.Second,
+	}
+
+	return &ListHunter{
+		scraperOptions: &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout, ContextReuse: ContextReuse},
+		client:         client,
+		source:         source,
+		links:          links,
+		pages:          make(map[string]*Page),
+		semaphore:      make(chan struct{}, MaxConcurrency),
+	}
+}
+
+func (l *ListHunter) SetHunterOptions(options *ScraperOptions) {
+	l.scraperOptions = options
+	l.semaphore = make(chan struct{}, l.scraperOptions.MaxConcurrency)
+	l.client = newClient(l.scraperOptions)
+}
+
+func (l *ListHunter) StartHunting() {
+	links := l.scraperOptions.filterLinks(l.links)
+	var urls []string
+	for _, link := range links {
+		urls = append(urls, link.url)
+	}
+	addCheckedLinks(l.pages, l.source, urls)
+
+	var wg sync.WaitGroup
+	for _, link := range links {
+		wg.Add(1)
+		go func(link foundLink) {
+			defer wg.Done()
+
+			deadLink, isDead := l.check(link)
+			if isDead {
+				// * Dead link found, add it to the pages map
+				l.pageMu.Lock()
+				addDeadLink(l.pages, l.scraperOptions, DeadLinkMsg{l.source, deadLink})
+				l.pageMu.Unlock()
+			}
+		}(link)
+	}
+	wg.Wait()
+}
+
+// check requests a URL of the list, duplicates checked at the same time share one request
+func (l *ListHunter) check(link foundLink) (DeadLink, bool) {
+	if u, err := url.Parse(link.url); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return DeadLink{URL: link.url, Reason: ReasonInvalidURL, Line: link.line, Column: link.column}, true
+	}
+
+	val, err, _ := l.flightGroup.Do(link.url, func() (interface{}, error) {
+		l.semaphore <- struct{}{}
+		defer func() {
+			<-l.semaphore
+		}()
+
+		log.Printf("fetching link %s", link.url)
+		statusCode, err := fetchStatus(l.client, link.url)
+		if err != nil {
+			return 0, &fetchError{err}
+		}
+		return statusCode, nil
+	})
+	return toDeadLink(l.scraperOptions, link, val, err)
+}
+
+func (l *ListHunter) GetResults() *map[string]*Page {
+	return &l.pages
+}
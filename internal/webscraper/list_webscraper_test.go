@@ -0,0 +1,70 @@
+package webscraper
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewListHunterFromReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []foundLink
+	}{
+		{
+			name:    "one per line",
+			content: "https://example.com/a\nhttps://example.com/b\n",
+			want:    []foundLink{{url: "https://example.com/a", line: 1, column: 1}, {url: "https://example.com/b", line: 2, column: 1}},
+		},
+		{
+			name:    "blank lines and comments",
+			content: "# links\n\n   \nhttps://example.com/a\n  # indented comment\n",
+			want:    []foundLink{{url: "https://example.com/a", line: 4, column: 1}},
+		},
+		{
+			name:    "indentation and extra columns",
+			content: "  https://example.com/a 404 Not Found\n\thttps://example.com/b\tpage\n",
+			want:    []foundLink{{url: "https://example.com/a", line: 1, column: 3}, {url: "https://example.com/b", line: 2, column: 2}},
+		},
+		{
+			name:    "byte order mark and CRLF",
+			content: "\ufeffhttps://example.com/a\r\nhttps://example.com/b\r\n",
+			want:    []foundLink{{url: "https://example.com/a", line: 1, column: 1}, {url: "https://example.com/b", line: 2, column: 1}},
+		},
+		{
+			name:    "no trailing newline",
+			content: "https://example.com/a",
+			want:    []foundLink{{url: "https://example.com/a", line: 1, column: 1}},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunter, err := NewListHunterFromReader("links.txt", strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := hunter.(*ListHunter)
+			if l.source != "links.txt" {
+				t.Errorf("source = %q, want links.txt", l.source)
+			}
+			if !reflect.DeepEqual(l.links, tt.want) {
+				t.Errorf("links = %+v, want %+v", l.links, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewListHunterFromReaderError(t *testing.T) {
+	readErr := errors.New("read failed")
+	if _, err := NewListHunterFromReader("links.txt", iotest.ErrReader(readErr)); !errors.Is(err, readErr) {
+		t.Errorf("error = %v, want %v", err, readErr)
+	}
+}
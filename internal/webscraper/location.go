@@ -0,0 +1,188 @@
+package webscraper
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type foundLink struct {
+	url      string // The absolute URL of the link
+	line     int    // The 1-based line of the link element, 0 if unknown
+	column   int    // The 1-based byte column of the link element, 0 if unknown
+	selector string // A CSS selector matching the link element, chained like frameSelector in iframes
+	frame    bool   // Set for the src of an iframe, whose document has links of the page too
+}
+
+// linkAttrs maps the tags with links to the attribute holding the link
+var linkAttrs = map[string]string{"a": "href", "iframe": "src"}
+
+type tagPosition struct {
+	line   int // The 1-based line where the tag starts
+	column int // The 1-based byte column where the tag starts
+}
+
+// positionKey identifies the tags whose positions can stand for each other
+type positionKey struct {
+	tag  string // The tag name
+	href string // The link attribute of the tag
+}
+
+// linkPositions returns the position of every start tag of linkAttrs in the
+// document by tag name and link, in source order
+func linkPositions(content []byte) map[positionKey][]tagPosition {
+	positions := make(map[positionKey][]tagPosition)
+	line, column := 1, 1
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return positions
+		}
+		raw := z.Raw()
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			name, hasAttr := z.TagName()
+			if linkAttr, ok := linkAttrs[string(name)]; ok {
+				k := positionKey{tag: string(name)}
+				for hasAttr {
+					var key, val []byte
+					key, val, hasAttr = z.TagAttr()
+					if string(key) == linkAttr {
+						k.href = string(val)
+					}
+				}
+				positions[k] = append(positions[k], tagPosition{line: line, column: column})
+			}
+		}
+		// Advance the position past the raw token
+		if n := bytes.Count(raw, []byte("\n")); n > 0 {
+			line += n
+			column = len(raw) - bytes.LastIndexByte(raw, '\n')
+		} else {
+			column += len(raw)
+		}
+	}
+}
+
+// parseLinks parses an HTML document and returns every <a href> and <iframe src>
+// together with its position in the source and a CSS selector for the element.
+// The positions come from a tokenizer pass and each element takes the next
+// unused position of a tag with the same name and link, so an element the
+// parser adds or moves only loses its own position.
+func parseLinks(body io.Reader) ([]foundLink, error) {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	positions := linkPositions(content)
+
+	doc, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var links []foundLink
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		linkAttr, ok := linkAttrs[n.Data]
+		if !ok {
+			continue
+		}
+		for _, a := range n.Attr {
+			if a.Key != linkAttr {
+				continue
+			}
+			link := foundLink{url: a.Val, selector: cssSelector(n), frame: n.Data == "iframe"}
+			k := positionKey{tag: n.Data, href: a.Val}
+			if tagPositions := positions[k]; len(tagPositions) > 0 {
+				link.line = tagPositions[0].line
+				link.column = tagPositions[0].column
+				positions[k] = tagPositions[1:]
+			}
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// cssSelector returns a CSS selector that uniquely matches the element,
+// anchored at the closest ancestor with an id
+func cssSelector(n *html.Node) string {
+	var parts []string
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if id := attr(n, "id"); id != "" {
+			parts = append(parts, "#"+id)
+			break
+		}
+		part := n.Data
+		index, count := 0, 0
+		if n.Parent != nil {
+			for sibling := n.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+				if sibling.Type == html.ElementNode && sibling.Data == n.Data {
+					count++
+					if sibling == n {
+						index = count
+					}
+				}
+			}
+		}
+		if count > 1 {
+			part += ":nth-of-type(" + strconv.Itoa(index) + ")"
+		}
+		parts = append(parts, part)
+	}
+
+	// The parts were collected from the element up to the root
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// frameSelector returns the selector of a link inside an iframe, chaining the
+// selector of the iframe and the selector of the link in its document
+func frameSelector(iframe, link string) string {
+	return iframe + " >> " + link
+}
+
+// cssSelectorScript is evaluated on a link element in the browser and returns
+// the same kind of selector as cssSelector. Elements in open shadow roots get
+// the selector of the shadow host chained like frameSelector.
+const cssSelectorScript = `(el) => {
+	const selectors = [];
+	let parts = [];
+	while (el && el.nodeType === Node.ELEMENT_NODE) {
+		let part = el.id ? "#" + el.id : el.localName;
+		if (!el.id && el.parentNode && el.parentNode.children) {
+			const siblings = Array.from(el.parentNode.children).filter((s) => s.localName === el.localName);
+			if (siblings.length > 1) {
+				part += ":nth-of-type(" + (siblings.indexOf(el) + 1) + ")";
+			}
+		}
+		parts.unshift(part);
+		if (!el.id && el.parentElement) {
+			el = el.parentElement;
+			continue;
+		}
+		// An id is only unique in its tree, continue from the shadow host if any
+		selectors.unshift(parts.join(" > "));
+		parts = [];
+		const root = el.getRootNode();
+		el = root instanceof ShadowRoot ? root.host : null;
+	}
+	return selectors.join(" >> ");
+}`
@@ -0,0 +1,85 @@
+package webscraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinkPositions(t *testing.T) {
+	content := "<p>\n  <a href=\"/a\">A</a> <a href='/b'>B</a>\n<iframe\n  src=\"/f\"></iframe><a href=\"/a\">again</a>\n<img src=\"/i\"><a>none</a>"
+	want := map[positionKey][]tagPosition{
+		{tag: "a", href: "/a"}:      {{line: 2, column: 3}, {line: 4, column: 21}},
+		{tag: "a", href: "/b"}:      {{line: 2, column: 22}},
+		{tag: "iframe", href: "/f"}: {{line: 3, column: 1}},
+		{tag: "a", href: ""}:        {{line: 5, column: 15}},
+	}
+	if got := linkPositions([]byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("linkPositions() = %v, want %v", got, want)
+	}
+}
+
+func TestParseLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []foundLink
+	}{
+		{
+			name:    "links and iframes",
+			content: "<html><body>\n<a href=\"/a\">A</a>\n<iframe src=\"/f\"></iframe>\n</body></html>",
+			want: []foundLink{
+				{url: "/a", line: 2, column: 1, selector: "html > body > a"},
+				{url: "/f", line: 3, column: 1, selector: "html > body > iframe", frame: true},
+			},
+		},
+		{
+			name:    "selectors use siblings of the same tag and the closest id",
+			content: "<div id=\"nav\"><ul><li><a href=\"/1\">1</a></li><li><a href=\"/2\">2</a></li></ul></div>",
+			want: []foundLink{
+				{url: "/1", line: 1, column: 23, selector: "#nav > ul > li:nth-of-type(1) > a"},
+				{url: "/2", line: 1, column: 50, selector: "#nav > ul > li:nth-of-type(2) > a"},
+			},
+		},
+		{
+			name:    "same link twice keeps source order",
+			content: "<a href=\"/a\">1</a>\n<a href=\"/a\">2</a>",
+			want: []foundLink{
+				{url: "/a", line: 1, column: 1, selector: "html > body > a:nth-of-type(1)"},
+				{url: "/a", line: 2, column: 1, selector: "html > body > a:nth-of-type(2)"},
+			},
+		},
+		{
+			name: "element moved by the parser keeps its position",
+			// The parser moves the link out of the table before the table
+			content: "<a href=\"/before\">b</a>\n<table><a href=\"/moved\">m</a><tr><td><a href=\"/cell\">c</a></td></tr></table>",
+			want: []foundLink{
+				{url: "/before", line: 1, column: 1, selector: "html > body > a:nth-of-type(1)"},
+				{url: "/moved", line: 2, column: 8, selector: "html > body > a:nth-of-type(2)"},
+				{url: "/cell", line: 2, column: 38, selector: "html > body > table > tbody > tr > td > a"},
+			},
+		},
+		{
+			name:    "links without the attribute are skipped",
+			content: "<a name=\"top\">top</a><iframe></iframe><img src=\"/i\">",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLinks(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLinks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrameSelector(t *testing.T) {
+	if got, want := frameSelector("#player", "html > body > a"), "#player >> html > body > a"; got != want {
+		t.Errorf("frameSelector() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package webscraper
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Login is a form-based login run once by the dynamic hunter before crawling.
+// The resulting cookies and local storage are shared by every page it opens.
+type Login struct {
+	URL          string      // The login page
+	Steps        []LoginStep // The steps run on the login page, in order
+	WaitFor      string      // Selector of an element that only appears once logged in, optional
+	WaitForURL   string      // Playwright glob pattern of the URL reached once logged in, optional
+	StorageState string      // File the session is saved to, optional
+}
+
+// LoginStep fills an input or clicks an element of the login page
+type LoginStep struct {
+	Fill  string // Selector of an input to fill with Value
+	Value string // The value to fill
+	Click string // Selector of an element to click
+}
+
+// login runs the login of the options and keeps the resulting session for
+// the browser contexts and the HTTP client of the hunter
+func (dh *DynamicHunter) login() error {
+	login := dh.scraperOptions.Login
+
+	context, err := newBrowserContext(*dh.browser, dh.scraperOptions, dh.device, nil)
+	if err != nil {
+		return err
+	}
+	defer context.Close()
+
+	page, err := context.NewPage()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("logging in at %s", login.URL)
+	if _, err := page.Goto(login.URL); err != nil {
+		return err
+	}
+	for i, step := range login.Steps {
+		switch {
+		case step.Fill != "":
+			err = page.Locator(step.Fill).Fill(step.Value)
+		case step.Click != "":
+			err = page.Locator(step.Click).Click()
+		}
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	// Without a condition, the page the last step led to has to finish loading
+	if login.WaitForURL != "" {
+		err = page.WaitForURL(login.WaitForURL)
+	} else if login.WaitFor == "" {
+		err = page.WaitForLoadState()
+	}
+	if err != nil {
+		return err
+	}
+	if login.WaitFor != "" {
+		if err := page.Locator(login.WaitFor).WaitFor(); err != nil {
+			return err
+		}
+	}
+
+	var paths []string
+	if login.StorageState != "" {
+		paths = append(paths, login.StorageState)
+	}
+	state, err := context.StorageState(paths...)
+	if err != nil {
+		return err
+	}
+	dh.storageState = state.ToOptionalStorageState()
+
+	// Binary files are requested without the browser
+	cookies := make([]*http.Cookie, 0, len(state.Cookies))
+	for _, c := range state.Cookies {
+		cookie := &http.Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, HttpOnly: c.HttpOnly, Secure: c.Secure}
+		if c.Expires > 0 {
+			cookie.Expires = time.Unix(int64(c.Expires), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	addCookies(dh.client, cookies)
+	return nil
+}
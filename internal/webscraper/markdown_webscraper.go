@@ -0,0 +1,300 @@
+package webscraper
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yingtu35/dead-link-hunter/internal/markdown"
+	"golang.org/x/sync/singleflight"
+)
+
+type MarkdownHunter struct {
+	scraperOptions *ScraperOptions               // The scraper options to use
+	client         *http.Client                  // The HTTP client to use
+	root           string                        // The Markdown file or directory to check
+	rootDir        string                        // The directory root-relative links resolve against
+	documents      map[string]*markdown.Document // A map to cache parsed Markdown documents
+	checkedUrls    map[string]urlCheck           // A map to keep track of checked URLs and their results
+	pages          map[string]*Page              // A map to keep track of crawled files and their dead links
+
+	semaphore chan struct{} // A semaphore to limit the number of concurrent requests
+
+	documentMu sync.Mutex // A mutex to protect documents
+	checkedMu  sync.Mutex // A mutex to protect checkedUrls
+	pageMu     sync.Mutex // A mutex to protect pages
+
+	flightGroup singleflight.Group // A singleflight group to avoid duplicate requests
+}
+
+type urlCheck struct {
+	statusCode int   // The HTTP status code of the URL
+	err        error // The error returned by the request, if any
+}
+
+func NewMarkdownHunter(root string) WebScraper {
+	info, err := os.Stat(root)
+	if err != nil {
+		log.Fatalf("Error reading Markdown source %s: %v", root, err)
+	}
+	rootDir := root
+	if !info.IsDir() {
+		rootDir = filepath.Dir(root)
+	}
+
+	client := &http.Client{
+		Timeout: DefaultTimeout * time.Second,
+	}
+
+	return &MarkdownHunter{
+		scraperOptions: &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout, ContextReuse: ContextReuse},
+		client:         client,
+		root:           root,
+		rootDir:        rootDir,
+		documents:      make(map[string]*markdown.Document),
+		checkedUrls:    make(map[string]urlCheck),
+		pages:          make(map[string]*Page),
+		semaphore:      make(chan struct{}, MaxConcurrency),
+	}
+}
+
+func (m *MarkdownHunter) SetHunterOptions(options *ScraperOptions) {
+	m.scraperOptions = options
+	m.semaphore = make(chan struct{}, m.scraperOptions.MaxConcurrency)
+	m.client = newClient(m.scraperOptions)
+}
+
+func (m *MarkdownHunter) StartHunting() {
+	files, err := m.findMarkdownFiles()
+	if err != nil {
+		log.Printf("Error listing Markdown files in %s: %v", m.root, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, file := range files {
+		wg.Add(1)
+		go func(file string) {
+			defer wg.Done()
+			m.hunt(file, &wg)
+		}(file)
+	}
+	wg.Wait()
+}
+
+func (m *MarkdownHunter) GetResults() *map[string]*Page {
+	return &m.pages
+}
+
+// findMarkdownFiles returns all .md files under the root, skipping hidden directories
+func (m *MarkdownHunter) findMarkdownFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(m.root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if p != m.root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(p), ".md") {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
+
+func (m *MarkdownHunter) hunt(file string, wg *sync.WaitGroup) {
+	log.Printf("checking file %s", file)
+	doc, err := m.getDocument(file)
+	if err != nil {
+		log.Printf("Error parsing %s: %v", file, err)
+		return
+	}
+
+	var links []markdown.Link
+	var checkedLinks []string
+	for _, link := range doc.Links {
+		if link.Target != "" && m.scraperOptions.isExcluded(link.Target) {
+			continue
+		}
+		links = append(links, link)
+		if isCheckable(link) {
+			checkedLinks = append(checkedLinks, displayTarget(link))
+		}
+	}
+	m.pageMu.Lock()
+	addCheckedLinks(m.pages, filepath.ToSlash(file), checkedLinks)
+	m.pageMu.Unlock()
+
+	for _, link := range links {
+		wg.Add(1)
+		go func(link markdown.Link) {
+			defer wg.Done()
+
+			statusCode, reason := m.checkLink(file, doc, link)
+			if reason == "" {
+				return
+			}
+			// * Dead link found, add it to the pages map
+			m.pageMu.Lock()
+			addDeadLink(m.pages, m.scraperOptions, DeadLinkMsg{filepath.ToSlash(file), DeadLink{
+				URL:        displayTarget(link),
+				StatusCode: statusCode,
+				Reason:     reason,
+				Line:       link.Line,
+				Column:     link.Column,
+			}})
+			m.pageMu.Unlock()
+		}(link)
+	}
+}
+
+// checkLink checks a single link found in file and returns its status code
+// and the reason it is dead, or an empty reason if the link is alive
+func (m *MarkdownHunter) checkLink(file string, doc *markdown.Document, link markdown.Link) (int, string) {
+	if link.Target == "" {
+		if link.Label != "" {
+			return 0, ReasonUndefinedReference
+		}
+		return 0, ReasonEmptyLink
+	}
+
+	u, err := url.Parse(link.Target)
+	if err != nil {
+		return 0, ReasonInvalidURL
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		statusCode, err := m.checkURL(link.Target)
+		if err != nil {
+			return 0, fetchErrorReason(err)
+		}
+		if m.scraperOptions.isDead(statusCode) {
+			return statusCode, statusReason(statusCode)
+		}
+		return statusCode, ""
+	case "":
+	default:
+		// mailto:, tel: and other schemes can't be checked
+		return 0, ""
+	}
+
+	// Same document anchor
+	if u.Path == "" {
+		if u.Fragment != "" && !hasAnchor(doc, u.Fragment) {
+			return 0, ReasonAnchorNotFound
+		}
+		return 0, ""
+	}
+
+	target := filepath.Join(filepath.Dir(file), filepath.FromSlash(u.Path))
+	if path.IsAbs(u.Path) {
+		target = filepath.Join(m.rootDir, filepath.FromSlash(u.Path))
+	}
+	if _, err := os.Stat(target); err != nil {
+		return 0, ReasonFileNotFound
+	}
+
+	if u.Fragment != "" && strings.EqualFold(filepath.Ext(target), ".md") {
+		targetDoc, err := m.getDocument(target)
+		if err != nil {
+			return 0, err.Error()
+		}
+		if !hasAnchor(targetDoc, u.Fragment) {
+			return 0, ReasonAnchorNotFound
+		}
+	}
+	return 0, ""
+}
+
+// checkURL requests an external URL once and caches its status code
+func (m *MarkdownHunter) checkURL(u string) (int, error) {
+	m.checkedMu.Lock()
+	if check, ok := m.checkedUrls[u]; ok {
+		m.checkedMu.Unlock()
+		return check.statusCode, check.err
+	}
+	m.checkedMu.Unlock()
+
+	val, err, _ := m.flightGroup.Do(u, func() (interface{}, error) {
+		statusCode, err := m.fetchURL(u)
+		m.checkedMu.Lock()
+		m.checkedUrls[u] = urlCheck{statusCode, err}
+		m.checkedMu.Unlock()
+		return statusCode, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return val.(int), nil
+}
+
+// fetchURL requests an external URL and returns its status code
+func (m *MarkdownHunter) fetchURL(u string) (int, error) {
+	m.semaphore <- struct{}{}
+	defer func() {
+		<-m.semaphore
+	}()
+
+	log.Printf("fetching link %s", u)
+	return fetchStatus(m.client, u)
+}
+
+// getDocument parses a Markdown file once and caches the result
+func (m *MarkdownHunter) getDocument(file string) (*markdown.Document, error) {
+	m.documentMu.Lock()
+	defer m.documentMu.Unlock()
+
+	if doc, ok := m.documents[file]; ok {
+		return doc, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := markdown.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	m.documents[file] = doc
+	return doc, nil
+}
+
+// isCheckable reports whether the link is checked, links with schemes such as
+// mailto: are skipped
+func isCheckable(link markdown.Link) bool {
+	u, err := url.Parse(link.Target)
+	return err != nil || u.Scheme == "" || u.Scheme == "http" || u.Scheme == "https"
+}
+
+// displayTarget returns the target a link is reported under, "[label]" for
+// an undefined reference or an empty link
+func displayTarget(link markdown.Link) string {
+	if link.Target == "" {
+		return "[" + link.Label + "]"
+	}
+	return link.Target
+}
+
+// hasAnchor reports whether the document defines the given URL fragment
+func hasAnchor(doc *markdown.Document, fragment string) bool {
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	return doc.Anchors[fragment] || doc.Anchors[strings.ToLower(fragment)]
+}
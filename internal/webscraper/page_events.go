@@ -0,0 +1,118 @@
+package webscraper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// pageEvents collects the failed subresource requests and the console messages
+// of a page while it is navigated. The listeners stay on the page when it is reused, so events are
+// only kept between start and stop.
+type pageEvents struct {
+	options *ScraperOptions
+
+	mu             sync.Mutex
+	recording      bool
+	failedRequests []DeadLink       // The failed subresource requests of the navigation
+	seen           map[string]bool  // The URLs of the failed requests, to report each once
+	messages       []ConsoleMessage // The console errors, warnings and uncaught exceptions of the navigation
+}
+
+// listen attaches the listeners of the events to the page
+func (e *pageEvents) listen(page playwright.Page) {
+	page.OnResponse(func(response playwright.Response) {
+		request := response.Request()
+		if request.IsNavigationRequest() || !e.options.isDead(response.Status()) {
+			return
+		}
+		e.addFailedRequest(DeadLink{
+			URL:          response.URL(),
+			StatusCode:   response.Status(),
+			Reason:       statusReason(response.Status()),
+			ResourceType: request.ResourceType(),
+		})
+	})
+	page.OnRequestFailed(func(request playwright.Request) {
+		failure := request.Failure()
+		// Requests cancelled by the page or by leaving it didn't fail, nor did blocked ones
+		if request.IsNavigationRequest() || failure == nil || strings.Contains(failure.Error(), "ERR_ABORTED") || strings.Contains(failure.Error(), "ERR_BLOCKED_BY_CLIENT") {
+			return
+		}
+		e.addFailedRequest(DeadLink{
+			URL:          request.URL(),
+			Reason:       fetchErrorReason(failure),
+			ResourceType: request.ResourceType(),
+		})
+	})
+	page.OnConsole(func(message playwright.ConsoleMessage) {
+		if message.Type() != "error" && message.Type() != "warning" {
+			return
+		}
+		var location string
+		if l := message.Location(); l != nil && l.URL != "" {
+			// Playwright numbers lines and columns from 0
+			location = fmt.Sprintf("%s:%d:%d", l.URL, l.LineNumber+1, l.ColumnNumber+1)
+		}
+		e.addMessage(ConsoleMessage{Type: message.Type(), Text: message.Text(), Location: location})
+	})
+	page.OnPageError(func(err error) {
+		e.addMessage(ConsoleMessage{Type: "exception", Text: err.Error(), Location: stackLocation(err)})
+	})
+}
+
+// stackLocation returns the innermost frame of the stack trace of a page error
+func stackLocation(err error) string {
+	var pageErr *playwright.Error
+	if !errors.As(err, &pageErr) {
+		return ""
+	}
+	for _, line := range strings.Split(pageErr.Stack, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "at ") {
+			return strings.TrimPrefix(line, "at ")
+		}
+	}
+	return ""
+}
+
+func (e *pageEvents) addMessage(message ConsoleMessage) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.recording {
+		e.messages = append(e.messages, message)
+	}
+}
+
+func (e *pageEvents) addFailedRequest(deadLink DeadLink) {
+	if e.options.isExcluded(deadLink.URL) {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.recording || e.seen[deadLink.URL] {
+		return
+	}
+	e.seen[deadLink.URL] = true
+	e.failedRequests = append(e.failedRequests, deadLink)
+}
+
+// start starts recording the events of a navigation
+func (e *pageEvents) start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recording = true
+	e.failedRequests = nil
+	e.messages = nil
+	e.seen = make(map[string]bool)
+}
+
+// stop stops recording and returns the failed requests and the console messages of the navigation
+func (e *pageEvents) stop() ([]DeadLink, []ConsoleMessage) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recording = false
+	return e.failedRequests, e.messages
+}
@@ -0,0 +1,191 @@
+package webscraper
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/rodaine/table"
+	"github.com/yingtu35/dead-link-hunter/pkg/domain"
+)
+
+// RenderDiff compares the links of a page fetched without JavaScript to the
+// links of the page rendered by the browser
+type RenderDiff struct {
+	Page         string   // The compared page
+	RenderedOnly []string // Links only found once JavaScript has run, sorted
+	StaticOnly   []string // Links of the served HTML that JavaScript removes, sorted
+	Common       int      // The number of links found both ways
+}
+
+// NeedsRendering reports whether some links of the page are only found with JavaScript
+func (d RenderDiff) NeedsRendering() bool {
+	return len(d.RenderedOnly) > 0
+}
+
+// RenderDiffer crawls a website like the hunters and compares the links of
+// every page with and without JavaScript rendering. Links are not checked.
+type RenderDiffer struct {
+	options *ScraperOptions // The scraper options to use
+	static  *StaticHunter   // Fetches the pages without rendering them
+	dynamic *DynamicHunter  // Renders the pages in the browser
+	url     string          // The URL to start comparing from
+	domain  string          // The domain of the URL
+
+	visited   map[string]bool // The pages already compared
+	visitedMu sync.Mutex      // A mutex to protect visited
+	diffs     []RenderDiff    // The comparisons of the pages
+	diffsMu   sync.Mutex      // A mutex to protect diffs
+
+	semaphore chan struct{} // A semaphore to limit the number of pages compared at once
+}
+
+func NewRenderDiffer(url string) *RenderDiffer {
+	d, err := domain.GetDomain(url)
+	if err != nil {
+		log.Fatalf("Error getting domain from URL: %v", err)
+	}
+	options := &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout, ContextReuse: ContextReuse}
+	return &RenderDiffer{
+		options:   options,
+		static:    NewStaticHunter(url).(*StaticHunter),
+		dynamic:   NewDynamicHunter(url).(*DynamicHunter),
+		url:       url,
+		domain:    d,
+		visited:   make(map[string]bool),
+		semaphore: make(chan struct{}, options.MaxConcurrency),
+	}
+}
+
+func (r *RenderDiffer) SetOptions(options *ScraperOptions) {
+	r.options = options
+	r.static.SetHunterOptions(options)
+	r.dynamic.SetHunterOptions(options)
+	r.semaphore = make(chan struct{}, options.MaxConcurrency)
+}
+
+// Start compares the pages up to the maximum depth and returns the
+// comparisons sorted by page
+func (r *RenderDiffer) Start() []RenderDiff {
+	r.dynamic.open()
+	defer r.dynamic.close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go r.compare(r.url, &wg, 0)
+	wg.Wait()
+
+	sort.Slice(r.diffs, func(i, j int) bool {
+		return r.diffs[i].Page < r.diffs[j].Page
+	})
+	return r.diffs
+}
+
+// compare compares the links of a page, then the pages it links to
+func (r *RenderDiffer) compare(url string, wg *sync.WaitGroup, curDepth int) {
+	defer wg.Done()
+
+	r.visitedMu.Lock()
+	if r.visited[url] {
+		r.visitedMu.Unlock()
+		return
+	}
+	r.visited[url] = true
+	r.visitedMu.Unlock()
+
+	r.semaphore <- struct{}{}
+	staticLinks, renderedLinks, ok := r.pageLinks(url)
+	<-r.semaphore
+	if !ok {
+		return
+	}
+
+	diff := diffLinks(url, staticLinks, renderedLinks)
+	r.diffsMu.Lock()
+	r.diffs = append(r.diffs, diff)
+	r.diffsMu.Unlock()
+
+	// Pages at the maximum depth or out of scope have their links checked but not extracted
+	for _, link := range sameDomainLinks(r.domain, append(staticLinks, renderedLinks...)) {
+		if domain.IsBinaryFileUrl(link) || !r.options.crawls(link, curDepth+1) {
+			continue
+		}
+		wg.Add(1)
+		go r.compare(link, wg, curDepth+1)
+	}
+}
+
+// pageLinks returns the links of a page fetched both ways, or false if either
+// way failed
+func (r *RenderDiffer) pageLinks(url string) ([]foundLink, []foundLink, bool) {
+	statusCode, staticLinks, err := r.static.pageLinks(url)
+	if err != nil || isDeadStatus(statusCode) {
+		log.Printf("Error fetching %s: status %d, %v", url, statusCode, err)
+		return nil, nil, false
+	}
+	statusCode, renderedLinks, err := r.dynamic.pageLinks(url)
+	if err != nil || isDeadStatus(statusCode) {
+		log.Printf("Error rendering %s: status %d, %v", url, statusCode, err)
+		return nil, nil, false
+	}
+	return r.options.filterLinks(staticLinks), r.options.filterLinks(renderedLinks), true
+}
+
+// diffLinks compares the distinct URLs of the links found both ways
+func diffLinks(page string, staticLinks, renderedLinks []foundLink) RenderDiff {
+	static, rendered := linkSet(staticLinks), linkSet(renderedLinks)
+	diff := RenderDiff{Page: page, RenderedOnly: []string{}, StaticOnly: []string{}}
+	for link := range rendered {
+		if static[link] {
+			diff.Common++
+		} else {
+			diff.RenderedOnly = append(diff.RenderedOnly, link)
+		}
+	}
+	for link := range static {
+		if !rendered[link] {
+			diff.StaticOnly = append(diff.StaticOnly, link)
+		}
+	}
+	slices.Sort(diff.RenderedOnly)
+	slices.Sort(diff.StaticOnly)
+	return diff
+}
+
+func linkSet(links []foundLink) map[string]bool {
+	set := make(map[string]bool, len(links))
+	for _, link := range links {
+		set[link.url] = true
+	}
+	return set
+}
+
+// PrintRenderDiffs prints the links that differ between the static and the
+// rendered pages as a table, followed by the number of pages needing rendering
+func PrintRenderDiffs(diffs []RenderDiff) {
+	log.Println()
+	needsRendering, differences := 0, 0
+	tbl := table.New("Page", "Found", "Link")
+	for _, diff := range diffs {
+		if diff.NeedsRendering() {
+			needsRendering++
+		}
+		differences += len(diff.RenderedOnly) + len(diff.StaticOnly)
+		page := diff.Page
+		for _, link := range diff.RenderedOnly {
+			tbl.AddRow(page, "rendered only", link)
+			page = ""
+		}
+		for _, link := range diff.StaticOnly {
+			tbl.AddRow(page, "static only", link)
+			page = ""
+		}
+	}
+	if differences == 0 {
+		log.Println("The links of every page are the same with and without JavaScript")
+	} else {
+		tbl.Print()
+	}
+	log.Printf("%d of %d pages have links that are only found with JavaScript", needsRendering, len(diffs))
+}
@@ -0,0 +1,135 @@
+package webscraper
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// highlightScript outlines the elements of dead links on a screenshot
+const highlightScript = `(elements) => {
+	for (const el of elements) {
+		el.style.outline = "3px solid #cf222e";
+		el.style.outlineOffset = "2px";
+	}
+}`
+
+// isFrameScript tells whether an element of a chained selector is an iframe
+const isFrameScript = `(el) => el.localName === "iframe" || el.localName === "frame"`
+
+// frameCheckTimeout is how long an element of a chained selector is waited
+// for, in milliseconds, the page was already rendered and expanded
+const frameCheckTimeout = 1000
+
+// takeScreenshots saves a screenshot of every page with dead links to the
+// screenshot directory, with the elements of the dead links outlined. Dead
+// links are only known once the crawl is over, so the pages are opened again.
+func (dh *DynamicHunter) takeScreenshots() {
+	// the paths are recorded in the reports, which are not necessarily
+	// written to the working directory, so they are made absolute
+	dir, err := filepath.Abs(dh.scraperOptions.Screenshots)
+	if err != nil {
+		log.Printf("Error resolving screenshot directory %s: %v", dh.scraperOptions.Screenshots, err)
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("Error creating screenshot directory %s: %v", dir, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, url := range PagesWithDeadLinks(dh.pages) {
+		wg.Add(1)
+		go func(url string, deadLinks []DeadLink) {
+			defer wg.Done()
+			dh.semaphore <- struct{}{}
+			defer func() {
+				<-dh.semaphore
+			}()
+
+			path := filepath.Join(dir, screenshotName(url))
+			if err := dh.screenshot(url, deadLinks, path); err != nil {
+				log.Printf("Error taking screenshot of %s: %v", url, err)
+				return
+			}
+			dh.pageMu.Lock()
+			dh.pages[url].Screenshot = path
+			dh.pageMu.Unlock()
+		}(url, dh.pages[url].DeadLinks)
+	}
+	wg.Wait()
+}
+
+// screenshot saves a full page screenshot of the page to path, rendered like
+// when its links were extracted
+func (dh *DynamicHunter) screenshot(url string, deadLinks []DeadLink, path string) error {
+	pooled, err := dh.pool.get()
+	if err != nil {
+		return err
+	}
+	page := pooled.page
+
+	log.Printf("taking screenshot of %s", url)
+	wait := dh.scraperOptions.waitStrategy(url)
+	if _, err := page.Goto(url, wait.gotoOptions()); err != nil {
+		dh.pool.discard(pooled)
+		return err
+	}
+	defer dh.pool.put(pooled)
+	wait.afterNavigation(page)
+	dh.scraperOptions.Expand.expand(page)
+
+	for _, deadLink := range deadLinks {
+		// Failed subresource requests have no element
+		if deadLink.Selector == "" {
+			continue
+		}
+		if _, err := chainLocator(page, deadLink.Selector).EvaluateAll(highlightScript); err != nil {
+			log.Printf("Error highlighting %s on %s: %v", deadLink.Selector, url, err)
+		}
+	}
+
+	_, err = page.Screenshot(playwright.PageScreenshotOptions{Path: &path, FullPage: playwright.Bool(true)})
+	return err
+}
+
+// chainLocator resolves a selector chained with " >> " like frameSelector.
+// Chaining already looks inside shadow roots, but the document of an iframe
+// is only entered through its content frame.
+func chainLocator(page playwright.Page, selector string) playwright.Locator {
+	parts := strings.Split(selector, " >> ")
+	locator := page.Locator(parts[0])
+	for _, part := range parts[1:] {
+		isFrame, err := locator.First().Evaluate(isFrameScript, nil, playwright.LocatorEvaluateOptions{Timeout: playwright.Float(frameCheckTimeout)})
+		if err == nil && isFrame == true {
+			locator = locator.ContentFrame().Locator(part)
+		} else {
+			locator = locator.Locator(part)
+		}
+	}
+	return locator
+}
+
+// screenshotName returns the file name of the screenshot of a page, made of
+// the URL and a hash of it so long URLs don't collide
+func screenshotName(url string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return fmt.Sprintf("%s-%08x.png", name, h.Sum32())
+}
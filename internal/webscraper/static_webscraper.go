@@ -1,6 +1,7 @@
 package webscraper
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"log"
@@ -9,26 +10,27 @@ import (
 	"sync"
 	"time"
 
-	"github.com/rodaine/table"
 	"github.com/yingtu35/dead-link-hunter/pkg/domain"
-	"golang.org/x/net/html"
 	"golang.org/x/sync/singleflight"
 )
 
 type StaticHunter struct {
-	scraperOptions     *ScraperOptions  // The scraper options to use
-	client             *http.Client     // The HTTP client to use
-	url                string           // The URL to start the hunting
-	protocol           string           // The protocol of the URL
-	domain             string           // The domain of the URL
-	visitedPages       map[string]bool  // A map to keep track of visited pages
-	deadUrls           map[string]bool  // A map to keep track of dead URLs
-	pagesWithDeadLinks map[string]*Page // A map to keep track of pages with dead links
+	scraperOptions *ScraperOptions  // The scraper options to use
+	client         *http.Client     // The HTTP client to use
+	url            string           // The URL to start the hunting
+	protocol       string           // The protocol of the URL
+	domain         string           // The domain of the URL
+	visitedPages   map[string]bool  // A map to keep track of visited pages
+	failedUrls     map[string]error // A map to keep track of URLs that could not be fetched
+	deadUrls       map[string]int   // A map to keep track of dead URLs and their status codes
+	pages          map[string]*Page // A map to keep track of crawled pages and their dead links
+
+	renderer *DynamicHunter // Renders the pages matching the escalation in hybrid mode, nil otherwise
 
 	semaphore chan struct{} // A semaphore to limit the number of concurrent requests
 
-	visitedMu sync.Mutex // A mutex to protect visitedPages and deadUrls
-	pageMu    sync.Mutex // A mutex to protect pagesWithDeadLinks
+	visitedMu sync.Mutex // A mutex to protect visitedPages, deadUrls and failedUrls
+	pageMu    sync.Mutex // A mutex to protect pages
 
 	flightGroup singleflight.Group // A singleflight group to avoid duplicate requests
 }
@@ -50,25 +52,36 @@ func NewStaticHunter(url string) WebScraper {
 	semaphore := make(chan struct{})
 
 	return &StaticHunter{
-		scraperOptions:     &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout},
-		client:             client,
-		url:                url,
-		protocol:           protocol,
-		domain:             domain,
-		visitedPages:       make(map[string]bool),
-		deadUrls:           make(map[string]bool),
-		pagesWithDeadLinks: make(map[string]*Page),
-		semaphore:          semaphore,
+		scraperOptions: &ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout, ContextReuse: ContextReuse},
+		client:         client,
+		url:            url,
+		protocol:       protocol,
+		domain:         domain,
+		visitedPages:   make(map[string]bool),
+		deadUrls:       make(map[string]int),
+		failedUrls:     make(map[string]error),
+		pages:          make(map[string]*Page),
+		semaphore:      semaphore,
 	}
 }
 
 func (d *StaticHunter) SetHunterOptions(options *ScraperOptions) {
 	d.scraperOptions = options
 	d.semaphore = make(chan struct{}, d.scraperOptions.MaxConcurrency)
-	d.client.Timeout = time.Duration(d.scraperOptions.Timeout) * time.Second
+	d.client = newClient(d.scraperOptions)
+	if d.renderer != nil {
+		d.renderer.SetHunterOptions(options)
+	}
 }
 
 func (d *StaticHunter) StartHunting() {
+	if d.renderer != nil {
+		d.renderer.open()
+		defer d.renderer.close()
+		// The client of the renderer has the cookies of the login, if any
+		d.client = d.renderer.client
+	}
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -86,7 +99,7 @@ func (d *StaticHunter) StartHunting() {
 		}
 
 		// Handle the result if needed
-		_, ok := val.(bool)
+		_, ok := val.(int)
 		if !ok {
 			log.Printf("Error type assertion for starting URL %s", d.url)
 			return
@@ -97,11 +110,20 @@ func (d *StaticHunter) StartHunting() {
 	wg.Wait()
 }
 
+// fetchFailed remembers that the URL could not be fetched and returns the error to report
+func (d *StaticHunter) fetchFailed(url string, err error) error {
+	fetchErr := &fetchError{err}
+	d.visitedMu.Lock()
+	d.failedUrls[url] = fetchErr
+	d.visitedMu.Unlock()
+	return fetchErr
+}
+
 func (d *StaticHunter) GetResults() *map[string]*Page {
-	return &d.pagesWithDeadLinks
+	return &d.pages
 }
 
-func (d *StaticHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (bool, error) {
+func (d *StaticHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (int, error) {
 	// Acquire the semaphore
 	d.semaphore <- struct{}{}
 	defer func() {
@@ -111,15 +133,15 @@ func (d *StaticHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (bool,
 	// Check if the URL has already been visited
 	d.visitedMu.Lock()
 	if d.visitedPages[url] {
-		isDead := d.deadUrls[url]
+		statusCode, err := d.deadUrls[url], d.failedUrls[url]
 		d.visitedMu.Unlock()
-		return isDead, nil
+		return statusCode, err
 	}
 	d.visitedPages[url] = true
 	d.visitedMu.Unlock()
 
 	if !domain.IsSameDomain(d.domain, url) {
-		return false, nil
+		return 0, nil
 	}
 
 	// Check if it's a binary file URL
@@ -128,127 +150,161 @@ func (d *StaticHunter) hunt(url string, wg *sync.WaitGroup, curDepth int) (bool,
 		log.Printf("fetching binary file %s", url)
 		resp, err := d.client.Head(url)
 		if err != nil {
-			return false, err
+			return 0, d.fetchFailed(url, err)
 		}
-		if resp.StatusCode > 299 {
+		if isDeadStatus(resp.StatusCode) {
 			d.visitedMu.Lock()
-			d.deadUrls[url] = true
+			d.deadUrls[url] = resp.StatusCode
 			d.visitedMu.Unlock()
-			return true, nil
 		}
-		return false, nil
+		return resp.StatusCode, nil
 	}
 
 	log.Printf("fetching page %s", url)
 	res, err := d.client.Get(url)
 	if err != nil {
 		log.Printf("Error fetching %s: %v", url, err)
-		return false, err
+		return 0, d.fetchFailed(url, err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode > 299 {
+	if isDeadStatus(res.StatusCode) {
 		d.visitedMu.Lock()
-		d.deadUrls[url] = true
+		d.deadUrls[url] = res.StatusCode
 		d.visitedMu.Unlock()
-		return true, nil
+		return res.StatusCode, nil
 	}
 
-	// Check if the current depth is greater than the maximum depth
-	if curDepth >= d.scraperOptions.MaxDepth {
-		return false, nil
+	// Pages beyond the maximum depth or out of scope are checked but not crawled
+	if !d.scraperOptions.crawls(url, curDepth) {
+		return 0, nil
 	}
 
-	links, err := d.getAllLinks(res.Body)
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		log.Printf("Error reading %s: %v", url, err)
+		return 0, err
+	}
+	links, err := d.getAllLinks(bytes.NewReader(content))
 	if err != nil {
 		log.Printf("Error parsing links from %s: %v", url, err)
-		return false, err
+		return 0, err
+	}
+	links = append(links, d.getFrameLinks(links)...)
+	if d.renderer != nil {
+		links = d.renderLinks(url, content, links)
 	}
+	links = d.scraperOptions.filterLinks(links)
+
+	d.pageMu.Lock()
+	addCheckedLinks(d.pages, url, sameDomainLinks(d.domain, links))
+	d.pageMu.Unlock()
 
 	for _, link := range links {
 		wg.Add(1)
-		go func(link string) {
+		go func(link foundLink) {
 			// Decrement the wait group counter when the function returns
 			defer wg.Done()
 
-			val, err, _ := d.flightGroup.Do(link, func() (interface{}, error) {
-				return d.hunt(link, wg, curDepth+1)
+			val, err, _ := d.flightGroup.Do(link.url, func() (interface{}, error) {
+				return d.hunt(link.url, wg, curDepth+1)
 			})
-			if err != nil {
-				log.Printf("Error hunting %s: %v", link, err)
-				return
-			}
-			isDead, ok := val.(bool)
-			if !ok {
-				log.Printf("Error type assertion %s", link)
-				return
-			}
+			deadLink, isDead := toDeadLink(d.scraperOptions, link, val, err)
 			if isDead {
-				// * Dead link found, add it to the pagesWithDeadLinks map
+				// * Dead link found, add it to the pages map
 				d.pageMu.Lock()
-				d.addDeadLink(DeadLinkMsg{url, link})
+				addDeadLink(d.pages, d.scraperOptions, DeadLinkMsg{url, deadLink})
 				d.pageMu.Unlock()
 			}
 		}(link)
 	}
-	return false, nil
+	return 0, nil
 }
 
-func (d *StaticHunter) addDeadLink(deadlink DeadLinkMsg) {
-	parentUrl := deadlink.parentUrl
-	url := deadlink.url
-	if _, ok := d.pagesWithDeadLinks[parentUrl]; parentUrl != "" && !ok {
-		d.pagesWithDeadLinks[parentUrl] = &Page{
-			DeadLinkCount: 0,
-			DeadLinks:     []string{},
+func (d *StaticHunter) getAllLinks(body io.Reader) ([]foundLink, error) {
+	parsedLinks, err := parseLinks(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []foundLink
+	for _, link := range parsedLinks {
+		linkURL, err := d.constructURL(link.url)
+		if err != nil {
+			continue
 		}
+		link.url = linkURL
+		links = append(links, link)
 	}
-	d.pagesWithDeadLinks[parentUrl].DeadLinkCount++
-	d.pagesWithDeadLinks[parentUrl].DeadLinks = append(d.pagesWithDeadLinks[parentUrl].DeadLinks, url)
+
+	return links, nil
 }
 
-func (d *StaticHunter) PrintResults() {
-	log.Println()
-	if len(d.pagesWithDeadLinks) == 0 {
-		log.Println("No dead links found")
-		return
+// pageLinks fetches a page like hunt and returns its status code and links
+func (d *StaticHunter) pageLinks(url string) (int, []foundLink, error) {
+	log.Printf("fetching page %s", url)
+	res, err := d.client.Get(url)
+	if err != nil {
+		return 0, nil, &fetchError{err}
 	}
+	defer res.Body.Close()
 
-	tbl := table.New("Page", "Counts", "Dead Links")
-	for url, page := range d.pagesWithDeadLinks {
-		for i, deadLink := range page.DeadLinks {
-			if i == 0 {
-				tbl.AddRow(url, page.DeadLinkCount, deadLink)
-			} else {
-				tbl.AddRow("", "", deadLink)
-			}
-		}
+	if isDeadStatus(res.StatusCode) {
+		return res.StatusCode, nil, nil
 	}
-	tbl.Print()
-}
-
-func (d *StaticHunter) getAllLinks(body io.Reader) ([]string, error) {
-	doc, err := html.Parse(body)
+	links, err := d.getAllLinks(res.Body)
 	if err != nil {
-		return nil, err
+		return res.StatusCode, nil, err
 	}
+	return res.StatusCode, append(links, d.getFrameLinks(links)...), nil
+}
+
+// getFrameLinks returns the links in the documents of the same-domain iframes
+// among links, which belong to the page of the iframes. Iframes nested in them
+// are checked but not searched. The status of a frame is remembered so hunt
+// checks its src without crawling it as a page of its own.
+func (d *StaticHunter) getFrameLinks(links []foundLink) []foundLink {
+	var frameLinks []foundLink
+	for _, frame := range links {
+		if !frame.frame || !domain.IsSameDomain(d.domain, frame.url) {
+			continue
+		}
+		d.visitedMu.Lock()
+		first := !d.visitedPages[frame.url]
+		d.visitedPages[frame.url] = true
+		d.visitedMu.Unlock()
 
-	var links []string
-	for n := range doc.Descendants() {
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, a := range n.Attr {
-				if a.Key == "href" {
-					link, err := d.constructURL(a.Val)
-					if err != nil {
-						continue
-					}
-					links = append(links, link)
-				}
+		log.Printf("fetching frame %s", frame.url)
+		res, err := d.client.Get(frame.url)
+		if err != nil {
+			// The iframe is reported as a dead link when it is checked
+			if first {
+				d.fetchFailed(frame.url, err)
+			}
+			continue
+		}
+		innerLinks, err := d.getAllLinks(res.Body)
+		res.Body.Close()
+		if isDeadStatus(res.StatusCode) {
+			if first {
+				d.visitedMu.Lock()
+				d.deadUrls[frame.url] = res.StatusCode
+				d.visitedMu.Unlock()
 			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		for _, link := range innerLinks {
+			// The positions are in the document of the frame
+			link.line, link.column = 0, 0
+			link.selector = frameSelector(frame.selector, link.selector)
+			link.frame = false
+			frameLinks = append(frameLinks, link)
 		}
 	}
-
-	return links, nil
+	return frameLinks
 }
 
 func (d *StaticHunter) constructURL(url string) (string, error) {
@@ -0,0 +1,60 @@
+package webscraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStaticHunterFrames(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<html><body><a href="/ok.html">ok</a><iframe src="/frame.html"></iframe><iframe src="/missing-frame.html"></iframe></body></html>`))
+	})
+	mux.HandleFunc("/ok.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body>ok</body></html>`))
+	})
+	var frameFetches atomic.Int32
+	mux.HandleFunc("/frame.html", func(w http.ResponseWriter, r *http.Request) {
+		frameFetches.Add(1)
+		w.Write([]byte(`<html><body><a href="/inframe-missing.html">missing</a><a href="/ok.html">ok</a></body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	hunter := NewStaticHunter(server.URL + "/")
+	hunter.SetHunterOptions(&ScraperOptions{MaxDepth: MaxDepth, MaxConcurrency: MaxConcurrency, Timeout: DefaultTimeout})
+	hunter.StartHunting()
+	pages := *hunter.GetResults()
+
+	root := server.URL + "/"
+	var got []string
+	for url, page := range pages {
+		for _, deadLink := range page.DeadLinks {
+			got = append(got, url+" "+deadLink.URL+" "+deadLink.Selector)
+		}
+	}
+	want := map[string]bool{
+		root + " " + server.URL + "/inframe-missing.html html > body > iframe:nth-of-type(1) >> html > body > a:nth-of-type(1)": true,
+		root + " " + server.URL + "/missing-frame.html html > body > iframe:nth-of-type(2)":                                     true,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got dead links %q, want %d", got, len(want))
+	}
+	for _, deadLink := range got {
+		if !want[deadLink] {
+			t.Errorf("unexpected dead link %q", deadLink)
+		}
+	}
+	if n := frameFetches.Load(); n != 1 {
+		t.Errorf("frame fetched %d times, want 1", n)
+	}
+	if _, ok := pages[server.URL+"/frame.html"]; ok {
+		t.Errorf("frame recorded as a page of its own")
+	}
+}
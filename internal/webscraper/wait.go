@@ -0,0 +1,60 @@
+package webscraper
+
+import (
+	"log"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// The load states a navigation can wait for
+const (
+	WaitLoad             = "load"
+	WaitDOMContentLoaded = "domcontentloaded"
+	WaitNetworkIdle      = "networkidle"
+)
+
+// WaitStates lists the supported load states
+var WaitStates = []string{WaitLoad, WaitDOMContentLoaded, WaitNetworkIdle}
+
+// WaitStrategy is how a dynamic page is waited for before its links are extracted
+type WaitStrategy struct {
+	Pattern  string        // Glob pattern of the pages it applies to, empty for every page
+	Until    string        // The load state the navigation waits for, one of WaitStates, default load
+	Selector string        // Selector of an element waited for after the navigation, empty for none
+	Delay    time.Duration // Fixed delay after the navigation and the selector
+}
+
+// waitStrategy returns the first wait strategy matching the page
+func (o *ScraperOptions) waitStrategy(url string) WaitStrategy {
+	for _, wait := range o.Wait {
+		if wait.Pattern == "" || MatchGlob(wait.Pattern, url) {
+			return wait
+		}
+	}
+	return WaitStrategy{Until: WaitLoad}
+}
+
+// gotoOptions returns the options of the navigation to a page
+func (w WaitStrategy) gotoOptions() playwright.PageGotoOptions {
+	until := w.Until
+	if until == "" {
+		until = WaitLoad
+	}
+	state := playwright.WaitUntilState(until)
+	return playwright.PageGotoOptions{WaitUntil: &state}
+}
+
+// afterNavigation waits for the selector and the delay of the strategy. A
+// selector that never appears is logged, the links rendered so far are still
+// extracted.
+func (w WaitStrategy) afterNavigation(page playwright.Page) {
+	if w.Selector != "" {
+		if err := page.Locator(w.Selector).First().WaitFor(); err != nil {
+			log.Printf("Error waiting for %s on %s: %v", w.Selector, page.URL(), err)
+		}
+	}
+	if w.Delay > 0 {
+		page.WaitForTimeout(float64(w.Delay.Milliseconds()))
+	}
+}
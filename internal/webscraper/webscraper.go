@@ -1,19 +1,136 @@
 package webscraper
 
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/rodaine/table"
+	"github.com/yingtu35/dead-link-hunter/pkg/domain"
+)
+
+// Reasons of dead links that don't come from an HTTP response
+const (
+	ReasonFileNotFound       = "file not found"
+	ReasonAnchorNotFound     = "anchor not found"
+	ReasonUndefinedReference = "undefined reference"
+	ReasonInvalidURL         = "invalid URL"
+	ReasonEmptyLink          = "empty link"
+	ReasonTimeout            = "timeout"
+)
+
+// Category is the kind of failure of a dead link
+type Category string
+
+const (
+	CategoryClientError   Category = "client error"   // 4xx responses
+	CategoryServerError   Category = "server error"   // 5xx responses
+	CategoryRedirect      Category = "redirect"       // 3xx responses that weren't followed
+	CategoryMissingFile   Category = "missing file"   // Relative links to files that don't exist
+	CategoryMissingAnchor Category = "missing anchor" // Links to anchors that don't exist
+	CategoryInvalidLink   Category = "invalid link"   // Malformed, empty links and undefined references
+	CategoryTimeout       Category = "timeout"        // Requests that timed out
+	CategoryRequestError  Category = "request error"  // Requests that failed without a response
+)
+
 type DeadLinkMsg struct {
 	parentUrl string
-	url       string
+	link      DeadLink
+}
+
+type DeadLink struct {
+	URL        string // The dead link
+	StatusCode int    // The HTTP status code of the link, 0 if there was no response
+	Reason     string // Why the link is considered dead
+	Line       int    // The line of the link in its source, 0 if unknown
+	Column     int    // The column of the link in its source, 0 if unknown
+	Selector   string // A CSS selector for the link element, a Playwright selector chained with " >> " in iframes and shadow roots, empty if not an HTML element
+
+	ResourceType string // The type of a subresource loaded by the page, e.g. image or fetch, empty for links
+
+	Suppression *Suppression // The suppression acknowledging the dead link, nil if it isn't suppressed
+
+	Baseline BaselineState // How the dead link compares to a baseline, empty without one
+}
+
+// BaselineState tells how a dead link compares to the dead links of a baseline
+type BaselineState string
+
+const (
+	BaselineNew      BaselineState = "new"      // Not in the baseline
+	BaselineExisting BaselineState = "existing" // Still broken since the baseline
+	BaselineFixed    BaselineState = "fixed"    // In the baseline but no longer found
+)
+
+// Suppression explains why a dead link is acknowledged instead of reported
+type Suppression struct {
+	Owner   string // Who is responsible for the suppression
+	Reason  string // Why the dead link is acknowledged
+	Expires string // The date after which the dead link is reported again, empty if never
 }
 
 type Page struct {
 	DeadLinkCount int
-	DeadLinks     []string
+	DeadLinks     []DeadLink
+	CheckedLinks  []string   // Every link on the page that was checked, dead or alive
+	Suppressed    []DeadLink // Dead links acknowledged by a suppression, not counted in DeadLinkCount
+	Fixed         []DeadLink // Dead links of the baseline that are no longer found, not counted in DeadLinkCount
+
+	ConsoleMessages []ConsoleMessage // JavaScript errors and warnings of the page in dynamic mode
+	Screenshot      string           // The absolute path of the screenshot of the page with its dead links outlined, empty if none
+}
+
+// ConsoleMessage is a console error or warning, or an uncaught exception, of a page
+type ConsoleMessage struct {
+	Type     string // error, warning or exception
+	Text     string // The message
+	Location string // The script URL, line and column of the message, empty if unknown
+}
+
+// DeadLinkTarget is a dead link together with every page that references it
+type DeadLinkTarget struct {
+	URL            string   // The dead link
+	StatusCode     int      // The HTTP status code of the link, 0 if there was no response
+	Reason         string   // Why the link is considered dead
+	ReferenceCount int      // The number of references to the link across all pages
+	Pages          []string // The pages referencing the link, sorted
+
+	Suppression *Suppression // The suppression acknowledging the references, nil for reported dead links
 }
 
 type ScraperOptions struct {
 	MaxDepth       int
 	MaxConcurrency int
 	Timeout        int
+	ContextReuse   int      // Navigations of a browser context before it is replaced, 1 disables reuse
+	Exclude        []string // Glob patterns of links that are not checked
+	Scope          []string // Glob patterns of the pages whose links are extracted, every page of the seed's domain if empty
+	AliveStatuses  []int    // Status codes above 299 that are not dead links, e.g. 429
+
+	Headers map[string]string // Headers sent with every request
+	Cookies []*http.Cookie    // Cookies sent to the hosts of their domain
+	Auth    []HostAuth        // Credentials sent to the hosts they match, first match wins
+	Login   *Login            // A login run before crawling in dynamic mode, nil if none
+
+	Wait   []WaitStrategy // How dynamic pages are waited for, the first strategy matching a page applies
+	Expand Expansion      // How the content of dynamic pages loaded on scroll or on click is loaded
+
+	Screenshots string // The directory screenshots of dynamic pages with dead links are saved to, empty for none
+
+	Escalation Escalation // Which pages are rendered by the browser in hybrid mode
+	Blocking   Blocking   // The requests dynamic pages don't make
+	Emulation  Emulation  // The device dynamic pages are rendered on
+
+	// OnDeadLink is called with every dead link as soon as it is found.
+	// Calls are never made concurrently.
+	OnDeadLink func(page string, deadLink DeadLink)
 }
 
 type WebScraper interface {
@@ -25,7 +142,450 @@ type WebScraper interface {
 
 	// GetResults returns the results of the hunting process
 	GetResults() *map[string]*Page
+}
+
+// isDeadStatus reports whether a response with the given status code is a dead link
+func isDeadStatus(statusCode int) bool {
+	return statusCode > 299
+}
+
+// isDead reports whether a response with the given status code is a dead link under the options
+func (o *ScraperOptions) isDead(statusCode int) bool {
+	return isDeadStatus(statusCode) && !slices.Contains(o.AliveStatuses, statusCode)
+}
+
+// isExcluded reports whether a link matches one of the exclude patterns
+func (o *ScraperOptions) isExcluded(link string) bool {
+	for _, pattern := range o.Exclude {
+		if MatchGlob(pattern, link) {
+			return true
+		}
+	}
+	return false
+}
+
+// crawls reports whether the links of a page at the given depth are
+// extracted. Seeds are always crawled, other pages must be in scope.
+func (o *ScraperOptions) crawls(url string, curDepth int) bool {
+	if curDepth >= o.MaxDepth {
+		return false
+	}
+	if curDepth == 0 || len(o.Scope) == 0 {
+		return true
+	}
+	for _, pattern := range o.Scope {
+		if MatchGlob(pattern, url) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLinks returns the links that are not excluded
+func (o *ScraperOptions) filterLinks(links []foundLink) []foundLink {
+	var result []foundLink
+	for _, link := range links {
+		if !o.isExcluded(link.url) {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// statusReason returns a human readable reason for a dead status code, e.g. "404 Not Found"
+func statusReason(statusCode int) string {
+	return strconv.Itoa(statusCode) + " " + http.StatusText(statusCode)
+}
+
+// fetchStatus requests a URL without reading its body and returns its status code
+func fetchStatus(client *http.Client, u string) (int, error) {
+	// Some servers don't support HEAD requests, fall back to GET
+	resp, err := client.Head(u)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if err != nil || isDeadStatus(resp.StatusCode) {
+		resp, err = client.Get(u)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+	}
+	return resp.StatusCode, nil
+}
+
+// fetchError is returned when a link could not be fetched at all, which makes it a dead link
+type fetchError struct {
+	err error
+}
+
+func (e *fetchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fetchError) Unwrap() error {
+	return e.err
+}
+
+// fetchErrorReason returns the reason of a dead link whose request failed without a response
+func fetchErrorReason(err error) string {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, playwright.ErrTimeout) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ReasonTimeout
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// toDeadLink turns the result of hunting a found link into a dead link.
+// It returns false if the link is alive or its status is unknown.
+func toDeadLink(options *ScraperOptions, link foundLink, val interface{}, err error) (DeadLink, bool) {
+	deadLink := DeadLink{URL: link.url, Line: link.line, Column: link.column, Selector: link.selector}
+
+	var fetchErr *fetchError
+	if errors.As(err, &fetchErr) {
+		log.Printf("Error hunting %s: %v", link.url, err)
+		deadLink.Reason = fetchErrorReason(fetchErr.err)
+		return deadLink, true
+	}
+	if err != nil {
+		log.Printf("Error hunting %s: %v", link.url, err)
+		return deadLink, false
+	}
+
+	statusCode, ok := val.(int)
+	if !ok {
+		log.Printf("Error type assertion %s", link.url)
+		return deadLink, false
+	}
+	deadLink.StatusCode = statusCode
+	deadLink.Reason = statusReason(statusCode)
+	return deadLink, options.isDead(statusCode)
+}
+
+// addDeadLink records a dead link found on the given parent page and passes it
+// to the OnDeadLink handler of the options, if any
+func addDeadLink(pages map[string]*Page, options *ScraperOptions, deadlink DeadLinkMsg) {
+	parentUrl := deadlink.parentUrl
+	if _, ok := pages[parentUrl]; parentUrl != "" && !ok {
+		pages[parentUrl] = newPage()
+	}
+	pages[parentUrl].DeadLinkCount++
+	pages[parentUrl].DeadLinks = append(pages[parentUrl].DeadLinks, deadlink.link)
+
+	if options.OnDeadLink != nil {
+		options.OnDeadLink(parentUrl, deadlink.link)
+	}
+}
+
+// addCheckedLinks records the links of a crawled page that are going to be checked
+func addCheckedLinks(pages map[string]*Page, parentUrl string, links []string) {
+	if _, ok := pages[parentUrl]; !ok {
+		pages[parentUrl] = newPage()
+	}
+	pages[parentUrl].CheckedLinks = append(pages[parentUrl].CheckedLinks, links...)
+}
+
+// sameDomainLinks returns the URLs of the links that are followed by a crawl of
+// the given domain, links to other domains are not checked
+func sameDomainLinks(d string, links []foundLink) []string {
+	var urls []string
+	for _, link := range links {
+		if domain.IsSameDomain(d, link.url) {
+			urls = append(urls, link.url)
+		}
+	}
+	return urls
+}
+
+func newPage() *Page {
+	return &Page{
+		DeadLinkCount: 0,
+		DeadLinks:     []DeadLink{},
+		CheckedLinks:  []string{},
+	}
+}
+
+// PagesWithDeadLinks returns the URLs of the pages that have dead links, sorted
+func PagesWithDeadLinks(pages map[string]*Page) []string {
+	var urls []string
+	for url, page := range pages {
+		if page.DeadLinkCount > 0 {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// PagesWithSuppressedLinks returns the URLs of the pages that have suppressed dead links, sorted
+func PagesWithSuppressedLinks(pages map[string]*Page) []string {
+	var urls []string
+	for url, page := range pages {
+		if len(page.Suppressed) > 0 {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// PagesWithConsoleMessages returns the URLs of the pages that have console messages, sorted
+func PagesWithConsoleMessages(pages map[string]*Page) []string {
+	var urls []string
+	for url, page := range pages {
+		if len(page.ConsoleMessages) > 0 {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// PagesWithFindings returns the URLs of the pages that have dead links, suppressed, fixed or not, or console messages, sorted
+func PagesWithFindings(pages map[string]*Page) []string {
+	var urls []string
+	for url, page := range pages {
+		if page.DeadLinkCount > 0 || len(page.Suppressed) > 0 || len(page.Fixed) > 0 || len(page.ConsoleMessages) > 0 {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// ConsoleMessageCount returns the number of console messages across all pages
+func ConsoleMessageCount(pages map[string]*Page) int {
+	count := 0
+	for _, page := range pages {
+		count += len(page.ConsoleMessages)
+	}
+	return count
+}
+
+// PagesWithAnyDeadLinks returns the URLs of the pages that have dead links, suppressed, fixed or not, sorted
+func PagesWithAnyDeadLinks(pages map[string]*Page) []string {
+	var urls []string
+	for url, page := range pages {
+		if page.DeadLinkCount > 0 || len(page.Suppressed) > 0 || len(page.Fixed) > 0 {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// SuppressedCount returns the number of suppressed dead links of all pages
+func SuppressedCount(pages map[string]*Page) int {
+	count := 0
+	for _, page := range pages {
+		count += len(page.Suppressed)
+	}
+	return count
+}
+
+// FixedCount returns the number of dead links of the baseline no longer found on all pages
+func FixedCount(pages map[string]*Page) int {
+	count := 0
+	for _, page := range pages {
+		count += len(page.Fixed)
+	}
+	return count
+}
+
+// String returns the suppression as "owner: reason (expires date)"
+func (s *Suppression) String() string {
+	if s.Expires == "" {
+		return s.Owner + ": " + s.Reason
+	}
+	return s.Owner + ": " + s.Reason + " (expires " + s.Expires + ")"
+}
+
+// PrintPages prints the pages with dead links as a table, followed by the suppressed dead links
+// and the console messages
+func PrintPages(pages map[string]*Page) {
+	defer printConsoleMessages(pages)
+	defer printSuppressed(pages)
+
+	log.Println()
+	urls := PagesWithDeadLinks(pages)
+	if len(urls) == 0 {
+		log.Println("No dead links found")
+		return
+	}
+
+	tbl := table.New("Page", "Counts", "Dead Links", "Status", "Location", "Selector")
+	for _, url := range urls {
+		page := pages[url]
+		for i, deadLink := range page.DeadLinks {
+			if i == 0 {
+				tbl.AddRow(url, page.DeadLinkCount, deadLink.URL, deadLink.Reason, deadLink.Location(), deadLink.Selector)
+			} else {
+				tbl.AddRow("", "", deadLink.URL, deadLink.Reason, deadLink.Location(), deadLink.Selector)
+			}
+		}
+	}
+	tbl.Print()
+}
+
+// printSuppressed prints the suppressed dead links as a table, if any
+func printSuppressed(pages map[string]*Page) {
+	urls := PagesWithSuppressedLinks(pages)
+	if len(urls) == 0 {
+		return
+	}
+
+	log.Println()
+	log.Printf("%d suppressed dead links", SuppressedCount(pages))
+	tbl := table.New("Page", "Dead Links", "Status", "Owner", "Reason", "Expires")
+	for _, url := range urls {
+		for i, deadLink := range pages[url].Suppressed {
+			page := url
+			if i > 0 {
+				page = ""
+			}
+			suppression := deadLink.Suppression
+			tbl.AddRow(page, deadLink.URL, deadLink.Reason, suppression.Owner, suppression.Reason, suppression.Expires)
+		}
+	}
+	tbl.Print()
+}
+
+// printConsoleMessages prints the console messages of the pages as a table, if any
+func printConsoleMessages(pages map[string]*Page) {
+	urls := PagesWithConsoleMessages(pages)
+	if len(urls) == 0 {
+		return
+	}
+
+	log.Println()
+	log.Printf("%d console messages", ConsoleMessageCount(pages))
+	tbl := table.New("Page", "Type", "Message", "Location")
+	for _, url := range urls {
+		for i, message := range pages[url].ConsoleMessages {
+			page := url
+			if i > 0 {
+				page = ""
+			}
+			tbl.AddRow(page, message.Type, message.Text, message.Location)
+		}
+	}
+	tbl.Print()
+}
+
+// GroupByTarget groups the dead links of all pages by their URL. The targets
+// are sorted by reference count, most referenced first.
+func GroupByTarget(pages map[string]*Page) []*DeadLinkTarget {
+	return groupByTarget(pages, func(page *Page) []DeadLink { return page.DeadLinks })
+}
+
+// GroupSuppressedByTarget groups the suppressed dead links of all pages by
+// their URL and suppression, sorted like GroupByTarget
+func GroupSuppressedByTarget(pages map[string]*Page) []*DeadLinkTarget {
+	return groupByTarget(pages, func(page *Page) []DeadLink { return page.Suppressed })
+}
+
+func groupByTarget(pages map[string]*Page, deadLinks func(page *Page) []DeadLink) []*DeadLinkTarget {
+	targets := make(map[string]*DeadLinkTarget)
+	for url, page := range pages {
+		for _, deadLink := range deadLinks(page) {
+			// Rules scoped to pages can acknowledge the same link differently
+			key := deadLink.URL
+			if deadLink.Suppression != nil {
+				key += "\x00" + deadLink.Suppression.String()
+			}
+			target, ok := targets[key]
+			if !ok {
+				target = &DeadLinkTarget{URL: deadLink.URL, StatusCode: deadLink.StatusCode, Reason: deadLink.Reason, Suppression: deadLink.Suppression}
+				targets[key] = target
+			}
+			target.ReferenceCount++
+			if !slices.Contains(target.Pages, url) {
+				target.Pages = append(target.Pages, url)
+			}
+		}
+	}
+
+	result := make([]*DeadLinkTarget, 0, len(targets))
+	for _, target := range targets {
+		sort.Strings(target.Pages)
+		result = append(result, target)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ReferenceCount != result[j].ReferenceCount {
+			return result[i].ReferenceCount > result[j].ReferenceCount
+		}
+		return result[i].URL < result[j].URL
+	})
+	return result
+}
+
+// PrintTargets prints the dead links grouped by URL as a table, followed by the suppressed dead links
+// and the console messages
+func PrintTargets(pages map[string]*Page) {
+	defer printConsoleMessages(pages)
+	defer printSuppressed(pages)
+
+	log.Println()
+	if len(PagesWithDeadLinks(pages)) == 0 {
+		log.Println("No dead links found")
+		return
+	}
+
+	tbl := table.New("Dead Link", "Status", "Counts", "Pages")
+	for _, target := range GroupByTarget(pages) {
+		for i, page := range target.Pages {
+			if i == 0 {
+				tbl.AddRow(target.URL, target.Reason, target.ReferenceCount, page)
+			} else {
+				tbl.AddRow("", "", "", page)
+			}
+		}
+	}
+	tbl.Print()
+}
+
+// Category returns the kind of failure of the dead link
+func (l DeadLink) Category() Category {
+	switch {
+	case l.StatusCode >= 500:
+		return CategoryServerError
+	case l.StatusCode >= 400:
+		return CategoryClientError
+	case l.StatusCode >= 300:
+		return CategoryRedirect
+	}
+	switch l.Reason {
+	case ReasonFileNotFound:
+		return CategoryMissingFile
+	case ReasonAnchorNotFound:
+		return CategoryMissingAnchor
+	case ReasonUndefinedReference, ReasonInvalidURL, ReasonEmptyLink:
+		return CategoryInvalidLink
+	case ReasonTimeout:
+		return CategoryTimeout
+	}
+	return CategoryRequestError
+}
+
+// Location returns the "line:column" position of the link in its source, or an empty string if unknown
+func (l DeadLink) Location() string {
+	if l.Line == 0 {
+		return ""
+	}
+	if l.Column == 0 {
+		return strconv.Itoa(l.Line)
+	}
+	return strconv.Itoa(l.Line) + ":" + strconv.Itoa(l.Column)
+}
 
-	// PrintResults prints the results of the hunting process
-	PrintResults()
+// String returns the message as "type: text (location)"
+func (m ConsoleMessage) String() string {
+	if m.Location == "" {
+		return m.Type + ": " + m.Text
+	}
+	return m.Type + ": " + m.Text + " (" + m.Location + ")"
 }